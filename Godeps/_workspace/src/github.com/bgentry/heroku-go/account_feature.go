@@ -59,7 +59,7 @@ func (c *Client) AccountFeatureList(lr *ListRange) ([]AccountFeature, error) {
 	}
 
 	var accountFeaturesRes []AccountFeature
-	return accountFeaturesRes, c.DoReq(req, &accountFeaturesRes)
+	return accountFeaturesRes, c.DoListReq(req, lr, &accountFeaturesRes)
 }
 
 // Update an existing account feature.
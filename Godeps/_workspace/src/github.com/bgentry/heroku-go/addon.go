@@ -92,7 +92,7 @@ func (c *Client) AddonList(appIdentity string, lr *ListRange) ([]Addon, error) {
 	}
 
 	var addonsRes []Addon
-	return addonsRes, c.DoReq(req, &addonsRes)
+	return addonsRes, c.DoListReq(req, lr, &addonsRes)
 }
 
 // Change add-on plan. Some add-ons may not support changing plans. In that
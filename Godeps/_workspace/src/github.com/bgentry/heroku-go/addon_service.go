@@ -46,5 +46,5 @@ func (c *Client) AddonServiceList(lr *ListRange) ([]AddonService, error) {
 	}
 
 	var addonServicesRes []AddonService
-	return addonServicesRes, c.DoReq(req, &addonServicesRes)
+	return addonServicesRes, c.DoListReq(req, lr, &addonServicesRes)
 }
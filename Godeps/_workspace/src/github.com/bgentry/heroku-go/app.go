@@ -114,7 +114,7 @@ func (c *Client) AppList(lr *ListRange) ([]App, error) {
 	}
 
 	var appsRes []App
-	return appsRes, c.DoReq(req, &appsRes)
+	return appsRes, c.DoListReq(req, lr, &appsRes)
 }
 
 // Update an existing app.
@@ -61,7 +61,7 @@ func (c *Client) AppFeatureList(appIdentity string, lr *ListRange) ([]AppFeature
 	}
 
 	var appFeaturesRes []AppFeature
-	return appFeaturesRes, c.DoReq(req, &appFeaturesRes)
+	return appFeaturesRes, c.DoListReq(req, lr, &appFeaturesRes)
 }
 
 // Update an existing app feature.
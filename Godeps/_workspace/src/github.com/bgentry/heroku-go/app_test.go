@@ -2,6 +2,7 @@ package heroku
 
 import (
 	"encoding/json"
+	"net/http"
 	"testing"
 	"time"
 
@@ -117,6 +118,35 @@ func TestAppListSuccess(t *testing.T) {
 	}
 }
 
+func TestAppListPages(t *testing.T) {
+	page1 := newTestRequest("GET", "/apps", "", testnet.TestResponse{
+		Status: 206,
+		Body:   "[" + appMarshaled + "," + appMarshaled + "]",
+		Header: http.Header{"Next-Range": {"]example..; max=2"}},
+	})
+	page1.Header.Set("Range", "name ..; max=2")
+	page2 := newTestRequest("GET", "/apps", "", testnet.TestResponse{
+		Status: 206,
+		Body:   "[" + appMarshaled + "," + appMarshaled + "]",
+		Header: http.Header{"Next-Range": {"]example2..; max=2"}},
+	})
+	page2.Header.Set("Range", "]example..; max=2")
+
+	ts, handler, c := newTestServerAndClient(t, page1, page2)
+	defer ts.Close()
+
+	apps, err := c.AppList(&ListRange{Field: "name", Max: 2, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !handler.AllRequestsCalled() {
+		t.Errorf("not all expected requests were called")
+	}
+	if len(apps) != 3 {
+		t.Fatalf("expected 3 apps, got %d", len(apps))
+	}
+}
+
 //
 // AppCreate()
 //
@@ -88,7 +88,7 @@ func (c *Client) AppTransferList(lr *ListRange) ([]AppTransfer, error) {
 	}
 
 	var appTransfersRes []AppTransfer
-	return appTransfersRes, c.DoReq(req, &appTransfersRes)
+	return appTransfersRes, c.DoListReq(req, lr, &appTransfersRes)
 }
 
 // Update an existing app transfer.
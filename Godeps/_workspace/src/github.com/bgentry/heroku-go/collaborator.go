@@ -85,5 +85,5 @@ func (c *Client) CollaboratorList(appIdentity string, lr *ListRange) ([]Collabor
 	}
 
 	var collaboratorsRes []Collaborator
-	return collaboratorsRes, c.DoReq(req, &collaboratorsRes)
+	return collaboratorsRes, c.DoListReq(req, lr, &collaboratorsRes)
 }
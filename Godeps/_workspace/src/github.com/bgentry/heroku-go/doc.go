@@ -95,5 +95,15 @@ API calls that list objects:
   apps, err = client.AppList(&heroku.ListRange{Field: "name", Max: 1000})
 
 Note Field is required when setting any range options.
+
+Each page holds at most Max records, and list calls follow the API's
+Next-Range header to fetch every page. Set Limit to stop after a number of
+records:
+
+  releases, err = client.ReleaseList("myapp", &heroku.ListRange{
+    Field: "version", Descending: true, Limit: 500,
+  })
+
+To process large lists a page at a time, use a ListIter.
 */
 package heroku
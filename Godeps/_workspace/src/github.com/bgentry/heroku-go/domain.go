@@ -69,5 +69,5 @@ func (c *Client) DomainList(appIdentity string, lr *ListRange) ([]Domain, error)
 	}
 
 	var domainsRes []Domain
-	return domainsRes, c.DoReq(req, &domainsRes)
+	return domainsRes, c.DoListReq(req, lr, &domainsRes)
 }
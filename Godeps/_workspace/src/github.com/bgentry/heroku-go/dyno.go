@@ -116,5 +116,5 @@ func (c *Client) DynoList(appIdentity string, lr *ListRange) ([]Dyno, error) {
 	}
 
 	var dynosRes []Dyno
-	return dynosRes, c.DoReq(req, &dynosRes)
+	return dynosRes, c.DoListReq(req, lr, &dynosRes)
 }
@@ -60,7 +60,7 @@ func (c *Client) FormationList(appIdentity string, lr *ListRange) ([]Formation,
 	}
 
 	var formationsRes []Formation
-	return formationsRes, c.DoReq(req, &formationsRes)
+	return formationsRes, c.DoListReq(req, lr, &formationsRes)
 }
 
 // Batch update process types
@@ -103,7 +103,7 @@ import (
       }
 
       var <%= variablecase(key + 's-res') %> []<%= titlecase(key) %>
-      return <%= variablecase(key + 's-res') %>, c.DoReq(req, &<%= variablecase(key + 's-res') %>)
+      return <%= variablecase(key + 's-res') %>, c.DoListReq(req, lr, &<%= variablecase(key + 's-res') %>)
     <%- end %>
   }
 
@@ -180,6 +180,13 @@ func (c *Client) APIReq(v interface{}, meth, path string, body interface{}) erro
 //   else       body is decoded into v as json
 //
 func (c *Client) DoReq(req *http.Request, v interface{}) error {
+	_, err := c.doReq(req, v)
+	return err
+}
+
+// doReq is like DoReq, but also returns the response, whose body has been
+// closed, so that callers can inspect its headers.
+func (c *Client) doReq(req *http.Request, v interface{}) (*http.Response, error) {
 	if c.Debug {
 		dump, err := httputil.DumpRequestOut(req, true)
 		if err != nil {
@@ -197,7 +204,7 @@ func (c *Client) DoReq(req *http.Request, v interface{}) error {
 
 	res, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer res.Body.Close()
 	if c.Debug {
@@ -210,7 +217,7 @@ func (c *Client) DoReq(req *http.Request, v interface{}) error {
 		}
 	}
 	if err = checkResp(res); err != nil {
-		return err
+		return res, err
 	}
 	switch t := v.(type) {
 	case nil:
@@ -219,14 +226,18 @@ func (c *Client) DoReq(req *http.Request, v interface{}) error {
 	default:
 		err = json.NewDecoder(res.Body).Decode(v)
 	}
-	return err
+	return res, err
 }
 
-// An Error represents a Heroku API error.
+// An Error represents a Heroku API error. Id identifies the kind of error,
+// e.g. "not_found" or "rate_limit", and is empty if the API's response could
+// not be parsed. URL points to documentation about the error, if any.
 type Error struct {
 	error
-	Id  string
-	URL string
+	Id         string `json:"id"`
+	Message    string `json:"message"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"status"`
 }
 
 type errorResp struct {
@@ -240,9 +251,16 @@ func checkResp(res *http.Response) error {
 		var e errorResp
 		err := json.NewDecoder(res.Body).Decode(&e)
 		if err != nil {
-			return errors.New("Unexpected error: " + res.Status)
+			msg := "Unexpected error: " + res.Status
+			return Error{error: errors.New(msg), Message: msg, StatusCode: res.StatusCode}
+		}
+		return Error{
+			error:      errors.New(e.Message),
+			Id:         e.Id,
+			Message:    e.Message,
+			URL:        e.URL,
+			StatusCode: res.StatusCode,
 		}
-		return Error{error: errors.New(e.Message), Id: e.Id, URL: e.URL}
 	}
 	if msg := res.Header.Get("X-Heroku-Warning"); msg != "" {
 		fmt.Fprintln(os.Stderr, strings.TrimSpace(msg))
@@ -250,22 +268,44 @@ func checkResp(res *http.Response) error {
 	return nil
 }
 
+// MaxPageSize is the largest number of records the API returns in a single
+// page of results.
+const MaxPageSize = 1000
+
+// A ListRange sets the Range header of a request that lists objects. Field
+// is the field to sort by, and is required when setting any other options.
+// Max is the number of records requested per page. Limit is the maximum
+// number of records to return in total, across all pages; zero means no
+// limit.
 type ListRange struct {
 	Field      string
 	Max        int
+	Limit      int
 	Descending bool
 	FirstId    string
 	LastId     string
 }
 
+// pageSize returns the number of records to request per page.
+func (lr *ListRange) pageSize() int {
+	max := lr.Max
+	if lr.Limit > 0 && (max == 0 || max > lr.Limit) {
+		max = lr.Limit
+	}
+	if max > MaxPageSize {
+		max = MaxPageSize
+	}
+	return max
+}
+
 func (lr *ListRange) SetHeader(req *http.Request) {
 	var hdrval string
 	if lr.Field != "" {
 		hdrval += lr.Field + " "
 	}
 	hdrval += lr.FirstId + ".." + lr.LastId
-	if lr.Max != 0 {
-		hdrval += fmt.Sprintf("; max=%d", lr.Max)
+	if max := lr.pageSize(); max != 0 {
+		hdrval += fmt.Sprintf("; max=%d", max)
 		if lr.Descending {
 			hdrval += ", "
 		}
@@ -278,3 +318,88 @@ func (lr *ListRange) SetHeader(req *http.Request) {
 	req.Header.Set("Range", hdrval)
 	return
 }
+
+// A ListIter iterates over the pages of results of a request that lists
+// objects. After each page, the API returns a Next-Range header if there are
+// more results, which ListIter uses to request the next page.
+//
+//   it := client.NewListIter(req)
+//   var page []heroku.App
+//   for it.Next(&page) {
+//     ...
+//   }
+//   if err := it.Err(); err != nil {
+//     ...
+//   }
+type ListIter struct {
+	c    *Client
+	req  *http.Request
+	done bool
+	err  error
+}
+
+func (c *Client) NewListIter(req *http.Request) *ListIter {
+	return &ListIter{c: c, req: req}
+}
+
+// Next requests the next page of results and decodes it into v, which should
+// be a pointer to a slice. It returns false when there are no more pages or
+// an error occurred.
+func (it *ListIter) Next(v interface{}) bool {
+	if it.done {
+		return false
+	}
+	res, err := it.c.doReq(it.req, v)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+	next := res.Header.Get("Next-Range")
+	if res.StatusCode != http.StatusPartialContent || next == "" {
+		it.done = true
+		return true
+	}
+	req := *it.req
+	req.Header = make(http.Header, len(it.req.Header))
+	for k, v := range it.req.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Range", next)
+	req.Header.Set("Request-Id", uuid.New())
+	it.req = &req
+	return true
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *ListIter) Err() error {
+	return it.err
+}
+
+// DoListReq submits a request that lists objects, following Next-Range
+// headers to fetch every page of results, and appends the results to v,
+// which must be a pointer to a slice. If lr has a Limit, at most that many
+// results are returned.
+func (c *Client) DoListReq(req *http.Request, lr *ListRange, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("DoListReq: v must be a pointer to a slice")
+	}
+	all := rv.Elem()
+	limit := 0
+	if lr != nil {
+		limit = lr.Limit
+	}
+
+	it := c.NewListIter(req)
+	page := reflect.New(all.Type())
+	for it.Next(page.Interface()) {
+		all.Set(reflect.AppendSlice(all, page.Elem()))
+		if limit > 0 && all.Len() >= limit {
+			all.Set(all.Slice(0, limit))
+			break
+		}
+		page.Elem().Set(reflect.Zero(all.Type()))
+	}
+	return it.Err()
+}
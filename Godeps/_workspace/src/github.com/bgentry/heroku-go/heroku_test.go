@@ -152,30 +152,40 @@ var respTests = []respTest{
 	{
 		newTestResponse(403, `{"id": "forbidden", "message": "You do not have access to the app myapp."}`),
 		Error{
-			error: errors.New("You do not have access to the app myapp."),
-			Id:    "forbidden",
-			URL:   "",
+			error:      errors.New("You do not have access to the app myapp."),
+			Id:         "forbidden",
+			Message:    "You do not have access to the app myapp.",
+			URL:        "",
+			StatusCode: 403,
 		},
 	},
 	{
 		newTestResponse(401, `{"id": "unauthorized", "message": "Long error message."}`),
 		Error{
-			error: errors.New("Long error message."),
-			Id:    "unauthorized",
-			URL:   "",
+			error:      errors.New("Long error message."),
+			Id:         "unauthorized",
+			Message:    "Long error message.",
+			URL:        "",
+			StatusCode: 401,
 		},
 	},
 	{
 		newTestResponse(422, `{"id": "invalid_params", "message": "Cannot scale to more than 5 PX size dynos per process type.", "url": "https://bit.ly/1gK1TvU"}`),
 		Error{
-			error: errors.New("Cannot scale to more than 5 PX size dynos per process type."),
-			Id:    "invalid_params",
-			URL:   "https://bit.ly/1gK1TvU",
+			error:      errors.New("Cannot scale to more than 5 PX size dynos per process type."),
+			Id:         "invalid_params",
+			Message:    "Cannot scale to more than 5 PX size dynos per process type.",
+			URL:        "https://bit.ly/1gK1TvU",
+			StatusCode: 422,
 		},
 	},
 	{
 		newTestResponse(500, `not valid json {} ""`),
-		errors.New("Unexpected error: Internal Server Error"),
+		Error{
+			error:      errors.New("Unexpected error: Internal Server Error"),
+			Message:    "Unexpected error: Internal Server Error",
+			StatusCode: 500,
+		},
 	},
 }
 
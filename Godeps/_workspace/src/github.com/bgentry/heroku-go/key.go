@@ -76,5 +76,5 @@ func (c *Client) KeyList(lr *ListRange) ([]Key, error) {
 	}
 
 	var keysRes []Key
-	return keysRes, c.DoReq(req, &keysRes)
+	return keysRes, c.DoListReq(req, lr, &keysRes)
 }
@@ -82,5 +82,5 @@ func (c *Client) LogDrainList(appIdentity string, lr *ListRange) ([]LogDrain, er
 	}
 
 	var logDrainsRes []LogDrain
-	return logDrainsRes, c.DoReq(req, &logDrainsRes)
+	return logDrainsRes, c.DoListReq(req, lr, &logDrainsRes)
 }
@@ -117,5 +117,5 @@ func (c *Client) OAuthAuthorizationList(lr *ListRange) ([]OAuthAuthorization, er
 	}
 
 	var oauthAuthorizationsRes []OAuthAuthorization
-	return oauthAuthorizationsRes, c.DoReq(req, &oauthAuthorizationsRes)
+	return oauthAuthorizationsRes, c.DoListReq(req, lr, &oauthAuthorizationsRes)
 }
@@ -80,7 +80,7 @@ func (c *Client) OAuthClientList(lr *ListRange) ([]OAuthClient, error) {
 	}
 
 	var oauthClientsRes []OAuthClient
-	return oauthClientsRes, c.DoReq(req, &oauthClientsRes)
+	return oauthClientsRes, c.DoListReq(req, lr, &oauthClientsRes)
 }
 
 // Update OAuth client
@@ -38,7 +38,7 @@ func (c *Client) OrganizationList(lr *ListRange) ([]Organization, error) {
 	}
 
 	var organizationsRes []Organization
-	return organizationsRes, c.DoReq(req, &organizationsRes)
+	return organizationsRes, c.DoListReq(req, lr, &organizationsRes)
 }
 
 // Set or Unset the organization as your default organization.
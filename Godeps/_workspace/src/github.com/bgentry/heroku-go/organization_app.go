@@ -118,7 +118,7 @@ func (c *Client) OrganizationAppList(lr *ListRange) ([]OrganizationApp, error) {
 	}
 
 	var organizationAppsRes []OrganizationApp
-	return organizationAppsRes, c.DoReq(req, &organizationAppsRes)
+	return organizationAppsRes, c.DoListReq(req, lr, &organizationAppsRes)
 }
 
 // List organization apps.
@@ -137,7 +137,7 @@ func (c *Client) OrganizationAppListForOrganization(organizationIdentity string,
 	}
 
 	var organizationAppsRes []OrganizationApp
-	return organizationAppsRes, c.DoReq(req, &organizationAppsRes)
+	return organizationAppsRes, c.DoListReq(req, lr, &organizationAppsRes)
 }
 
 // Info for an organization app.
@@ -94,5 +94,5 @@ func (c *Client) OrganizationAppCollaboratorList(appIdentity string, lr *ListRan
 	}
 
 	var organizationAppCollaboratorsRes []OrganizationAppCollaborator
-	return organizationAppCollaboratorsRes, c.DoReq(req, &organizationAppCollaboratorsRes)
+	return organizationAppCollaboratorsRes, c.DoListReq(req, lr, &organizationAppCollaboratorsRes)
 }
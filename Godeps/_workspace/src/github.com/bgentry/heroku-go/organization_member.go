@@ -65,5 +65,5 @@ func (c *Client) OrganizationMemberList(organizationIdentity string, lr *ListRan
 	}
 
 	var organizationMembersRes []OrganizationMember
-	return organizationMembersRes, c.DoReq(req, &organizationMembersRes)
+	return organizationMembersRes, c.DoListReq(req, lr, &organizationMembersRes)
 }
@@ -64,5 +64,5 @@ func (c *Client) PlanList(addonServiceIdentity string, lr *ListRange) ([]Plan, e
 	}
 
 	var plansRes []Plan
-	return plansRes, c.DoReq(req, &plansRes)
+	return plansRes, c.DoListReq(req, lr, &plansRes)
 }
@@ -49,5 +49,5 @@ func (c *Client) RegionList(lr *ListRange) ([]Region, error) {
 	}
 
 	var regionsRes []Region
-	return regionsRes, c.DoReq(req, &regionsRes)
+	return regionsRes, c.DoListReq(req, lr, &regionsRes)
 }
@@ -62,7 +62,7 @@ func (c *Client) ReleaseList(appIdentity string, lr *ListRange) ([]Release, erro
 	}
 
 	var releasesRes []Release
-	return releasesRes, c.DoReq(req, &releasesRes)
+	return releasesRes, c.DoListReq(req, lr, &releasesRes)
 }
 
 // Create new release. The API cannot be used to create releases on Bamboo apps.
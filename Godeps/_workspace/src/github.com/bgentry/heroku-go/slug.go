@@ -35,6 +35,12 @@ type Slug struct {
 	// size of slug, in bytes
 	Size *int `json:"size"`
 
+	// identity of slug stack
+	Stack struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"stack"`
+
 	// when slug was updated
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -92,7 +92,7 @@ func (c *Client) SSLEndpointList(appIdentity string, lr *ListRange) ([]SSLEndpoi
 	}
 
 	var sslEndpointsRes []SSLEndpoint
-	return sslEndpointsRes, c.DoReq(req, &sslEndpointsRes)
+	return sslEndpointsRes, c.DoListReq(req, lr, &sslEndpointsRes)
 }
 
 // Update an existing SSL endpoint.
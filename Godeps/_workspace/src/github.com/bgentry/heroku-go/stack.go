@@ -50,5 +50,5 @@ func (c *Client) StackList(lr *ListRange) ([]Stack, error) {
 	}
 
 	var stacksRes []Stack
-	return stacksRes, c.DoReq(req, &stacksRes)
+	return stacksRes, c.DoListReq(req, lr, &stacksRes)
 }
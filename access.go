@@ -2,23 +2,26 @@ package main
 
 import (
 	"log"
-	"os"
 	"sort"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
 
 var cmdAccess = &Command{
 	Run:      runAccess,
-	Usage:    "access",
+	Usage:    "access [--limit <n>]",
 	NeedsApp: true,
 	Category: "access",
+	Raw:      true,
 	Short:    "list access permissions" + extra,
 	Long: `
 List access permissions for an app. The owner is shown first, and
 collaborators are then listed alphabetically.
 
+Options:
+
+    --limit <n>  list at most n collaborators
+
 Examples:
 
     $ hk access
@@ -27,13 +30,17 @@ Examples:
 `,
 }
 
+func init() {
+	cmdAccess.Flag.IntVar(&flagLimit, "limit", 0, "maximum number of collaborators to list")
+}
+
 func runAccess(cmd *Command, args []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	// Org collaborators works for all apps and gives us exactly the data we need.
@@ -41,8 +48,8 @@ func runAccess(cmd *Command, args []string) {
 	must(err)
 
 	sort.Sort(accessByRoleAndEmail(orgCollaborators))
-	for _, oc := range orgCollaborators {
-		listRec(w,
+	for _, oc := range orgCollaborators[:limit(len(orgCollaborators))] {
+		w.Rec(oc,
 			oc.User.Email,
 			oc.Role,
 			prettyTime{oc.UpdatedAt},
@@ -60,7 +67,8 @@ func (a accessByRoleAndEmail) Less(i, j int) bool {
 
 var cmdAccessAdd = &Command{
 	Run:      runAccessAdd,
-	Usage:    "access-add [-s] <email>",
+	Plan:     planAccessAdd,
+	Usage:    "access-add [-s] [--dry-run] <email>",
 	NeedsApp: true,
 	Category: "access",
 	Short:    "give a user access to an app" + extra,
@@ -69,7 +77,8 @@ Give another Heroku user access to an app.
 
 Options:
 
-    -s  add user silently with no email notification
+    -s         add user silently with no email notification
+    --dry-run  show the changes without making them
 
 Examples:
 
@@ -89,11 +98,7 @@ func init() {
 
 func runAccessAdd(cmd *Command, args []string) {
 	appname := mustApp()
-	if len(args) != 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
-	}
-	user := args[0]
+	user := mustParseAccessArgs(cmd, args)
 
 	var err error
 	if isAppInOrg(mustGetOrgApp(appname)) {
@@ -107,15 +112,35 @@ func runAccessAdd(cmd *Command, args []string) {
 	log.Printf("Granted %s access to %s.", user, appname)
 }
 
+func planAccessAdd(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	user := mustParseAccessArgs(cmd, args)
+
+	p := newPlan(appname)
+	p.Change("collaborator", "", user)
+	body := map[string]interface{}{"user": user, "silent": flagSilent}
+	if isAppInOrg(mustGetOrgApp(appname)) {
+		p.Request("POST", "/organizations/apps/"+appname+"/collaborators", body)
+	} else {
+		p.Request("POST", "/apps/"+appname+"/collaborators", body)
+	}
+	return p
+}
+
 var cmdAccessRemove = &Command{
 	Run:      runAccessRemove,
-	Usage:    "access-remove <email>",
+	Plan:     planAccessRemove,
+	Usage:    "access-remove [--dry-run] <email>",
 	NeedsApp: true,
 	Category: "access",
 	Short:    "remove a user's access to an app" + extra,
 	Long: `
 Remove another Heroku user's access to an app.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Examples:
 
     $ hk access-remove user@me.com
@@ -125,11 +150,7 @@ Examples:
 
 func runAccessRemove(cmd *Command, args []string) {
 	appname := mustApp()
-	if len(args) != 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
-	}
-	user := args[0]
+	user := mustParseAccessArgs(cmd, args)
 
 	if isAppInOrg(mustGetOrgApp(appname)) {
 		must(client.OrganizationAppCollaboratorDelete(appname, user))
@@ -138,3 +159,25 @@ func runAccessRemove(cmd *Command, args []string) {
 	}
 	log.Printf("Removed %s from access to %s.", user, appname)
 }
+
+func planAccessRemove(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	user := mustParseAccessArgs(cmd, args)
+
+	p := newPlan(appname)
+	p.Change("collaborator", user, "")
+	if isAppInOrg(mustGetOrgApp(appname)) {
+		p.Request("DELETE", "/organizations/apps/"+appname+"/collaborators/"+user, nil)
+	} else {
+		p.Request("DELETE", "/apps/"+appname+"/collaborators/"+user, nil)
+	}
+	return p
+}
+
+func mustParseAccessArgs(cmd *Command, args []string) (user string) {
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	return args[0]
+}
@@ -2,10 +2,7 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"log"
-	"os"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -14,6 +11,7 @@ var cmdAccountFeatures = &Command{
 	Run:      runAccountFeatures,
 	Usage:    "account-features",
 	Category: "account",
+	Raw:      true,
 	Short:    "list account features" + extra,
 	Long: `
 Account-features lists Heroku Labs features for your account.
@@ -28,9 +26,9 @@ Example:
 func runAccountFeatures(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	features, err := client.AccountFeatureList(&heroku.ListRange{Field: "name"})
@@ -39,13 +37,13 @@ func runAccountFeatures(cmd *Command, args []string) {
 	listAccountFeatures(w, features)
 }
 
-func listAccountFeatures(w io.Writer, features []heroku.AccountFeature) {
+func listAccountFeatures(w *listWriter, features []heroku.AccountFeature) {
 	for _, f := range features {
 		enabled := " "
 		if f.Enabled {
 			enabled = "+"
 		}
-		listRec(w,
+		w.Rec(f,
 			enabled,
 			f.Name,
 		)
@@ -56,6 +54,7 @@ var cmdAccountFeatureInfo = &Command{
 	Run:      runAccountFeatureInfo,
 	Usage:    "account-feature-info <feature>",
 	Category: "account",
+	Raw:      true,
 	Short:    "show info for an account feature" + extra,
 	Long: `
 Shows detailed info for a Heroku Labs feature on an account.
@@ -70,10 +69,13 @@ Example:
 func runAccountFeatureInfo(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	feature, err := client.AccountFeatureInfo(args[0])
 	must(err)
+	if printRaw(feature) {
+		return
+	}
 	fmt.Printf("Name:         %s\n", feature.Name)
 	fmt.Printf("Docs:         %s\n", feature.DocURL)
 	fmt.Printf("Enabled:      %t\n", feature.Enabled)
@@ -98,7 +100,7 @@ Example:
 func runAccountFeatureEnable(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	featureName := args[0]
 	feature, err := client.AccountFeatureUpdate(featureName, true)
@@ -124,7 +126,7 @@ Example:
 func runAccountFeatureDisable(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	featureName := args[0]
 	feature, err := client.AccountFeatureUpdate(featureName, false)
@@ -2,13 +2,10 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"math/big"
-	"os"
 	"sort"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -18,6 +15,7 @@ var cmdAddons = &Command{
 	Usage:    "addons [<service>:<plan>...]",
 	NeedsApp: true,
 	Category: "add-on",
+	Raw:      true,
 	Short:    "list addons",
 	Long: `
 Lists addons.
@@ -38,7 +36,7 @@ func runAddons(cmd *Command, names []string) {
 	addons, err := client.AddonList(appname, nil)
 	must(err)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	for i, s := range names {
@@ -66,12 +64,12 @@ func addonMatch(a heroku.Addon, names []string) bool {
 	return false
 }
 
-func listAddon(w io.Writer, a heroku.Addon) {
+func listAddon(w *listWriter, a heroku.Addon) {
 	name := a.Name
 	if name == "" {
 		name = "[unnamed]"
 	}
-	listRec(w,
+	w.Rec(a,
 		name,
 		a.Plan.Name,
 		prettyTime{a.CreatedAt},
@@ -80,13 +78,18 @@ func listAddon(w io.Writer, a heroku.Addon) {
 
 var cmdAddonAdd = &Command{
 	Run:      runAddonAdd,
-	Usage:    "addon-add <service>[:<plan>] [<config>=<value>...]",
+	Plan:     planAddonAdd,
+	Usage:    "addon-add [--dry-run] <service>[:<plan>] [<config>=<value>...]",
 	NeedsApp: true,
 	Category: "add-on",
 	Short:    "add an addon",
 	Long: `
 Adds an addon to an app.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Examples:
 
     $ hk addon-add heroku-postgresql
@@ -99,17 +102,39 @@ Examples:
 
 func runAddonAdd(cmd *Command, args []string) {
 	appname := mustApp()
+	plan, opts := mustParseAddonAddArgs(cmd, appname, args)
+	addon, err := client.AddonCreate(appname, plan, &opts)
+	must(err)
+	log.Printf("Added %s to %s as %s.", addon.Plan.Name, appname, addon.Name)
+}
+
+func planAddonAdd(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	plan, opts := mustParseAddonAddArgs(cmd, appname, args)
+
+	p := newPlan(appname)
+	p.Change("addon", "", plan)
+	body := map[string]interface{}{"plan": plan}
+	if opts.Config != nil {
+		body["config"] = *opts.Config
+	}
+	p.Request("POST", "/apps/"+appname+"/addons", body)
+	return p
+}
+
+// mustParseAddonAddArgs parses the arguments of hk addon-add, resolving the
+// config of Heroku Postgres addons against the app's config vars.
+func mustParseAddonAddArgs(cmd *Command, appname string, args []string) (plan string, opts heroku.AddonCreateOpts) {
 	if len(args) == 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	plan := args[0]
-	var opts heroku.AddonCreateOpts
+	plan = args[0]
 	if len(args) > 1 {
 		config, err := parseAddonAddConfig(args[1:])
 		if err != nil {
 			log.Println(err)
-			os.Exit(2)
+			exit(2)
 		}
 		// if this is a postgres addon, resolve fork/follow/rollback args
 		provider, _ := splitProviderAndPlan(plan)
@@ -126,9 +151,7 @@ func runAddonAdd(cmd *Command, args []string) {
 		}
 		opts = heroku.AddonCreateOpts{Config: config}
 	}
-	addon, err := client.AddonCreate(appname, plan, &opts)
-	must(err)
-	log.Printf("Added %s to %s as %s.", addon.Plan.Name, appname, addon.Name)
+	return plan, opts
 }
 
 func splitProviderAndPlan(providerAndPlan string) (provider string, plan string) {
@@ -162,14 +185,20 @@ func parseAddonAddConfig(config []string) (*map[string]string, error) {
 
 var cmdAddonDestroy = &Command{
 	Run:      runAddonDestroy,
-	Usage:    "addon-destroy <name>",
+	Plan:     planAddonDestroy,
+	Usage:    "addon-destroy [--confirm <app>] [--dry-run] <name>",
 	NeedsApp: true,
 	Category: "add-on",
 	Short:    "destroy an addon",
 	Long: `
 Removes an addon from an app, permanently destroying any data
 stored by that addon. The command will prompt for confirmation,
-or accept confirmation via stdin.
+or accept confirmation via stdin or the --confirm flag.
+
+Options:
+
+    --confirm <app>  confirm destroying the addon without a prompt
+    --dry-run        show the changes without making them
 
 Examples:
 
@@ -180,28 +209,52 @@ Examples:
 
     $ echo myapp | hk addon-destroy redistogo
     Destroyed redistogo on myapp.
+
+    $ hk addon-destroy --confirm myapp redistogo
+    Destroyed redistogo on myapp.
 `,
 }
 
+func init() {
+	cmdAddonDestroy.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+}
+
 func runAddonDestroy(cmd *Command, args []string) {
 	appname := mustApp()
+	name := mustParseAddonDestroyArgs(cmd, args)
+
+	warning := "This will destroy %s on %s. Please type %q to continue:"
+	mustConfirm(appname, fmt.Sprintf(warning, name, appname, appname), appname)
+
+	checkAddonError(client.AddonDelete(appname, name))
+	log.Printf("Destroyed %s on %s.", name, appname)
+}
+
+func planAddonDestroy(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	name := mustParseAddonDestroyArgs(cmd, args)
+	addon, err := client.AddonInfo(appname, name)
+	checkAddonError(err)
+
+	p := newPlan(appname)
+	p.Change(addon.Name, addon.Plan.Name, "")
+	p.Request("DELETE", "/apps/"+appname+"/addons/"+name, nil)
+	return p
+}
+
+func mustParseAddonDestroyArgs(cmd *Command, args []string) (name string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	name := args[0]
+	name = args[0]
 	if strings.IndexRune(name, ':') != -1 {
 		// specified an addon with plan name, unsupported in v3
 		log.Println("Please specify an addon name, not a plan name.")
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-
-	warning := "This will destroy %s on %s. Please type %q to continue:"
-	mustConfirm(fmt.Sprintf(warning, name, appname, appname), appname)
-
-	checkAddonError(client.AddonDelete(appname, name))
-	log.Printf("Destroyed %s on %s.", name, appname)
+	return name
 }
 
 var cmdAddonOpen = &Command{
@@ -225,7 +278,7 @@ func runAddonOpen(cmd *Command, args []string) {
 	appname := mustApp()
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	name := args[0]
 	// look up addon to make sure it exists and to get plan name
@@ -236,13 +289,18 @@ func runAddonOpen(cmd *Command, args []string) {
 
 var cmdAddonPlan = &Command{
 	Run:      runAddonPlan,
-	Usage:    "addon-plan <name> <plan>",
+	Plan:     planAddonPlan,
+	Usage:    "addon-plan [--dry-run] <name> <plan>",
 	NeedsApp: true,
 	Category: "add-on",
 	Short:    "change an addon's plan" + extra,
 	Long: `
 Change an addon's plan. Not all add-on providers support this
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Examples:
 
     $ hk addon-plan redistogo small
@@ -252,22 +310,39 @@ Examples:
 
 func runAddonPlan(cmd *Command, args []string) {
 	appname := mustApp()
+	_, name, plan, serviceAndPlan := mustResolveAddonPlan(cmd, appname, args)
+
+	a, err := client.AddonUpdate(appname, name, serviceAndPlan)
+	checkAddonError(err)
+	log.Printf("Changed %s plan to %s on %s.", a.Name, plan, appname)
+}
+
+func planAddonPlan(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	addon, name, _, serviceAndPlan := mustResolveAddonPlan(cmd, appname, args)
+
+	p := newPlan(appname)
+	p.Change(addon.Name, addon.Plan.Name, serviceAndPlan)
+	p.Request("PATCH", "/apps/"+appname+"/addons/"+name, map[string]string{"plan": serviceAndPlan})
+	return p
+}
+
+// mustResolveAddonPlan parses the arguments of hk addon-plan, and returns
+// the addon and the full service:plan name of the new plan.
+func mustResolveAddonPlan(cmd *Command, appname string, args []string) (addon *heroku.Addon, name, plan, serviceAndPlan string) {
 	if len(args) != 2 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	name := args[0]
-	plan := args[1]
+	name = args[0]
+	plan = args[1]
 
 	addon, err := client.AddonInfo(appname, name)
 	checkAddonError(err)
 
 	// assemble service:plan string
-	serviceAndPlan := strings.Split(addon.Plan.Name, ":")[0] + ":" + plan
-
-	a, err := client.AddonUpdate(appname, name, serviceAndPlan)
-	checkAddonError(err)
-	log.Printf("Changed %s plan to %s on %s.", a.Name, plan, appname)
+	serviceAndPlan = strings.Split(addon.Plan.Name, ":")[0] + ":" + plan
+	return
 }
 
 func checkAddonError(err error) {
@@ -277,7 +352,7 @@ func checkAddonError(err error) {
 		} else {
 			printFatal(err.Error())
 		}
-		os.Exit(2)
+		exit(2)
 	}
 }
 
@@ -285,6 +360,7 @@ var cmdAddonServices = &Command{
 	Run:      runAddonServices,
 	Usage:    "addon-services",
 	Category: "add-on",
+	Raw:      true,
 	Short:    "list addon services" + extra,
 	Long: `
 Lists available addon services.
@@ -302,13 +378,16 @@ Examples:
 func runAddonServices(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	services, err := client.AddonServiceList(nil)
 	must(err)
 
+	w := newListWriter()
+	defer w.Flush()
+
 	for _, s := range services {
-		fmt.Println(s.Name)
+		w.Rec(s, s.Name)
 	}
 }
 
@@ -316,6 +395,7 @@ var cmdAddonPlans = &Command{
 	Run:      runAddonPlans,
 	Usage:    "addon-plans <service>",
 	Category: "add-on",
+	Raw:      true,
 	Short:    "list addon plans" + extra,
 	Long: `
 Lists available addon plans for an addon provider.
@@ -338,18 +418,18 @@ Examples:
 func runAddonPlans(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	service := args[0]
 	plans, err := client.PlanList(service, nil)
 	must(err)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	sort.Sort(addonPlansByPrice(plans))
 	for _, p := range plans {
-		listRec(w,
+		w.Rec(p,
 			strings.TrimPrefix(p.Name, service+":"),
 			addonPlanPriceString(p),
 		)
@@ -47,7 +47,7 @@ Examples:
 func runAPI(cmd *Command, args []string) {
 	if len(args) != 2 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	method := strings.ToUpper(args[0])
 	var body io.Reader
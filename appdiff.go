@@ -0,0 +1,230 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var flagAppDiffValues bool
+
+var cmdAppDiff = &Command{
+	Run:      runAppDiff,
+	Usage:    "app-diff [--values] <app> <app>",
+	Category: "app",
+	Raw:      true,
+	Short:    "show differences between two apps" + extra,
+	Long: `
+App-diff compares the setup of two apps, such as staging and
+production, and lists the differences: config vars, add-ons,
+formation, features, stack, region, domains and log drains. It
+exits with status 1 if the apps differ, so it can be used to
+catch drift in CI.
+
+Config vars are compared by name only, unless --values is given.
+Values of config vars that look like secrets (see 'hk export')
+are never shown.
+
+Options:
+
+    --values  also compare the values of config vars
+
+Examples:
+
+    $ hk app-diff myapp-staging myapp
+                             myapp-staging          myapp
+    stack                    cedar-14               cedar
+    web                      1:1X                   2:2X
+    addon.heroku-postgresql  heroku-postgresql:dev  heroku-postgresql:crane
+    addon.redistogo          redistogo:nano         -
+    config.DEBUG             set                    -
+    feature.preboot          false                  true
+    domain.www.example.com   -                      yes
+
+    $ hk app-diff --values --json myapp-staging myapp
+    [
+      {
+        "name": "config.RACK_ENV",
+        "a": "staging",
+        "b": "production"
+      }
+    ]
+`,
+}
+
+func init() {
+	cmdAppDiff.Flag.BoolVar(&flagAppDiffValues, "values", false, "compare config var values")
+}
+
+// An appDifference is a setting that differs between two apps. An empty A
+// or B means the setting is absent from that app.
+type appDifference struct {
+	Name string `json:"name"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+func runAppDiff(cmd *Command, args []string) {
+	if len(args) != 2 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+
+	var (
+		lives [2]*liveApp
+		errs  [2]error
+		wg    sync.WaitGroup
+	)
+	for i, name := range args {
+		wg.Add(1)
+		go func(i int, appname string) {
+			defer wg.Done()
+			lives[i], errs[i] = fetchLiveApp(appname)
+		}(i, name)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		must(err)
+	}
+
+	diffs := diffApps(lives[0], lives[1], flagAppDiffValues)
+	w := newListWriter()
+	if len(diffs) > 0 && !rawOutput() {
+		listRec(w.tw, "", args[0], args[1])
+	}
+	for _, d := range diffs {
+		w.Rec(d, d.Name, orDash(d.A), orDash(d.B))
+	}
+	w.Flush()
+	if len(diffs) > 0 {
+		exit(1)
+	}
+}
+
+// diffApps returns the settings that differ between apps a and b. Config vars
+// are compared by name, or by value if values is true.
+func diffApps(a, b *liveApp, values bool) []appDifference {
+	var diffs []appDifference
+	add := func(name string, av, bv string) {
+		if av != bv {
+			diffs = append(diffs, appDifference{name, av, bv})
+		}
+	}
+	ma, mb := a.Manifest(), b.Manifest()
+
+	add("region", ma.Region, mb.Region)
+	add("stack", ma.Stack, mb.Stack)
+
+	fa, fb := make(map[string]string), make(map[string]string)
+	for _, f := range ma.Formation {
+		fa[f.Type] = strconv.Itoa(f.Quantity) + ":" + f.Size
+	}
+	for _, f := range mb.Formation {
+		fb[f.Type] = strconv.Itoa(f.Quantity) + ":" + f.Size
+	}
+	for _, k := range unionKeys(fa, fb) {
+		add(k, fa[k], fb[k])
+	}
+
+	aa, ab := addonPlansByService(ma.Addons), addonPlansByService(mb.Addons)
+	for _, k := range unionKeys(aa, ab) {
+		add("addon."+k, aa[k], ab[k])
+	}
+
+	for _, k := range unionKeys(a.Config, b.Config) {
+		av, aok := a.Config[k]
+		bv, bok := b.Config[k]
+		if aok == bok && (!values || av == bv) {
+			continue
+		}
+		diffs = append(diffs, appDifference{
+			"config." + k,
+			configDiffValue(k, av, aok, values),
+			configDiffValue(k, bv, bok, values),
+		})
+	}
+
+	xa, xb := make(map[string]string), make(map[string]string)
+	for k, v := range ma.Features {
+		xa[k] = strconv.FormatBool(v)
+	}
+	for k, v := range mb.Features {
+		xb[k] = strconv.FormatBool(v)
+	}
+	for _, k := range unionKeys(xa, xb) {
+		add("feature."+k, xa[k], xb[k])
+	}
+
+	da, db := setOf(ma.Domains), setOf(mb.Domains)
+	for _, k := range unionKeys(da, db) {
+		add("domain."+k, da[k], db[k])
+	}
+	la, lb := setOf(ma.Drains), setOf(mb.Drains)
+	for _, k := range unionKeys(la, lb) {
+		add("drain."+k, la[k], lb[k])
+	}
+	return diffs
+}
+
+// configDiffValue returns how a config var is shown by app-diff: "set" if
+// values aren't being compared, and "(hidden)" if it looks like a secret.
+func configDiffValue(name, value string, ok, values bool) string {
+	switch {
+	case !ok:
+		return ""
+	case !values:
+		return "set"
+	case isSecretConfig(name, value):
+		return "(hidden)"
+	}
+	return value
+}
+
+// addonPlansByService maps each add-on service to its plans, comma-separated
+// if there are several add-ons of the same service.
+func addonPlansByService(addons []manifestAddon) map[string]string {
+	plans := make(map[string][]string)
+	for _, a := range addons {
+		s := addonService(a.Plan)
+		plans[s] = append(plans[s], a.Plan)
+	}
+	m := make(map[string]string, len(plans))
+	for s, p := range plans {
+		sort.Strings(p)
+		m[s] = strings.Join(p, ",")
+	}
+	return m
+}
+
+func setOf(a []string) map[string]string {
+	m := make(map[string]string, len(a))
+	for _, s := range a {
+		m[s] = "yes"
+	}
+	return m
+}
+
+// unionKeys returns the keys of a and b, sorted.
+func unionKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
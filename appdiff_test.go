@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestDiffApps(t *testing.T) {
+	a := &liveApp{
+		App:       &heroku.App{Name: "myapp-staging"},
+		Formation: []heroku.Formation{{Type: "web", Quantity: 1, Size: "1X"}},
+		Config:    map[string]string{"RACK_ENV": "staging", "DEBUG": "1", "API_TOKEN": "a"},
+		Domains:   []heroku.Domain{{Hostname: "myapp-staging.herokuapp.com"}},
+	}
+	b := &liveApp{
+		App:       &heroku.App{Name: "myapp"},
+		Formation: []heroku.Formation{{Type: "web", Quantity: 2, Size: "1X"}},
+		Config:    map[string]string{"RACK_ENV": "production", "API_TOKEN": "b"},
+		Domains:   []heroku.Domain{{Hostname: "myapp.herokuapp.com"}, {Hostname: "www.example.com"}},
+	}
+
+	want := []appDifference{
+		{"web", "1:1X", "2:1X"},
+		{"config.DEBUG", "set", ""},
+		{"domain.www.example.com", "", "yes"},
+	}
+	if got := diffApps(a, b, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffApps(values=false) = %v, want %v", got, want)
+	}
+
+	want = []appDifference{
+		{"web", "1:1X", "2:1X"},
+		{"config.API_TOKEN", "(hidden)", "(hidden)"},
+		{"config.DEBUG", "1", ""},
+		{"config.RACK_ENV", "staging", "production"},
+		{"domain.www.example.com", "", "yes"},
+	}
+	if got := diffApps(a, b, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffApps(values=true) = %v, want %v", got, want)
+	}
+}
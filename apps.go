@@ -1,11 +1,8 @@
 package main
 
 import (
-	"io"
-	"os"
 	"sort"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
@@ -13,13 +10,19 @@ import (
 
 var cmdApps = &Command{
 	Run:      runApps,
-	Usage:    "apps [<name>...]",
+	Usage:    "apps [-o <org>] [--limit <n>] [<name>...]",
 	Category: "app",
+	Raw:      true,
 	Short:    "list apps",
 	Long: `
 Lists apps. Shows the app name, owner, and last release time (or
 time the app was created, if it's never been released).
 
+Options:
+
+    -o <org>     list apps in the given organization
+    --limit <n>  list at most n apps, in alphabetical order
+
 Examples:
 
     $ hk apps
@@ -33,15 +36,16 @@ Examples:
 
 func init() {
 	cmdApps.Flag.StringVarP(&flagOrgName, "org", "o", "", "organization name")
+	cmdApps.Flag.IntVar(&flagLimit, "limit", 0, "maximum number of apps to list")
 }
 
 func runApps(cmd *Command, names []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 	var apps []hkapp
 	if len(names) == 0 {
 		var err error
-		apps, err = getAppList(flagOrgName)
+		apps, err = getAppList(orgName())
 		must(err)
 	} else {
 		appch := make(chan *heroku.App, len(names))
@@ -75,23 +79,33 @@ func runApps(cmd *Command, names []string) {
 
 func getAppList(orgName string) ([]hkapp, error) {
 	if orgName != "" {
-		apps, err := client.OrganizationAppListForOrganization(orgName, &heroku.ListRange{Field: "name", Max: 1000})
+		apps, err := client.OrganizationAppListForOrganization(orgName, &heroku.ListRange{
+			Field: "name",
+			Max:   1000,
+			Limit: flagLimit,
+		})
 		if err != nil {
 			return nil, err
 		}
 		return fromOrgApps(apps), nil
 	}
 
-	apps, err := client.AppList(&heroku.ListRange{Field: "name", Max: 1000})
+	apps, err := client.AppList(&heroku.ListRange{
+		Field: "name",
+		Max:   1000,
+		Limit: flagLimit,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return fromApps(apps), nil
 }
 
-func printAppList(w io.Writer, apps []hkapp) {
+func printAppList(w *listWriter, apps []hkapp) {
 	sort.Sort(appsByName(apps))
-	abbrevEmailApps(apps)
+	if !rawOutput() {
+		abbrevEmailApps(apps)
+	}
 	for _, a := range apps {
 		if a.Name != "" {
 			listApp(w, a)
@@ -126,7 +140,7 @@ func abbrevEmailApps(apps []hkapp) {
 	}
 }
 
-func listApp(w io.Writer, a hkapp) {
+func listApp(w *listWriter, a hkapp) {
 	t := a.CreatedAt
 	if a.ReleasedAt != nil {
 		t = *a.ReleasedAt
@@ -135,7 +149,7 @@ func listApp(w io.Writer, a hkapp) {
 	if orgOrEmail == "" {
 		orgOrEmail = a.OwnerEmail
 	}
-	listRec(w,
+	w.Rec(a.raw,
 		a.Name,
 		abbrev(orgOrEmail, 20),
 		a.Region,
@@ -166,6 +180,10 @@ type hkapp struct {
 	Stack                        string
 	UpdatedAt                    time.Time
 	WebURL                       string
+
+	// raw is the heroku.App or heroku.OrganizationApp this was made from,
+	// for --json and --format.
+	raw interface{}
 }
 
 func fromApp(app heroku.App) (happ hkapp) {
@@ -190,6 +208,7 @@ func fromApp(app heroku.App) (happ hkapp) {
 		Stack:                        app.Stack.Name,
 		UpdatedAt:                    app.UpdatedAt,
 		WebURL:                       app.WebURL,
+		raw:                          app,
 	}
 }
 
@@ -222,6 +241,7 @@ func fromOrgApp(oapp heroku.OrganizationApp) (happ hkapp) {
 		Stack:                        oapp.Stack.Name,
 		UpdatedAt:                    oapp.UpdatedAt,
 		WebURL:                       oapp.WebURL,
+		raw:                          oapp,
 	}
 }
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"net/url"
 	"os"
 	"strings"
@@ -34,7 +35,7 @@ Example:
 func runAuthorize(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	if os.Getenv("HEROKU_AGENT_SOCK") == "" {
@@ -42,6 +43,7 @@ func runAuthorize(cmd *Command, args []string) {
 			"HEROKU_AGENT_SOCK")
 	}
 
+	mustBeInteractive("a two-factor auth code", "")
 	var twoFactorCode string
 	fmt.Printf("Enter two-factor auth code: ")
 	if _, err := fmt.Scanln(&twoFactorCode); err != nil {
@@ -68,19 +70,12 @@ var cmdCreds = &Command{
 }
 
 func runCreds(cmd *Command, args []string) {
-	var err error
-
-	nrc, err = hkclient.LoadNetRc()
-	if err != nil {
-		printFatal(err.Error())
-	}
-
 	u, err := url.Parse(apiURL)
 	if err != nil {
 		printFatal("could not parse API url: " + err.Error())
 	}
 
-	user, pass, err := nrc.GetCreds(u)
+	user, pass, err := hkclient.ProfileCreds(loadCreds(), u, profile)
 	if err != nil {
 		printFatal("could not get credentials: " + err.Error())
 	}
@@ -88,9 +83,72 @@ func runCreds(cmd *Command, args []string) {
 	fmt.Println(user, pass)
 }
 
+var cmdCredsMigrate = &Command{
+	Run:      runCredsMigrate,
+	Usage:    "creds-migrate",
+	Category: "hk",
+	Short:    "move credentials out of .netrc" + extra,
+	Long: `
+Moves the credentials of every profile from .netrc to the
+configured credential store, and removes them from .netrc.
+
+The credential store is selected with the HKCREDSTORE env var or
+the hk.credential-store git config setting. It may be one of:
+
+    netrc           plaintext .netrc file (the default)
+    encrypted       file encrypted with a passphrase (~/.hk/credentials)
+    secret-service  freedesktop.org Secret Service, via secret-tool
+
+Example:
+
+    $ git config --global hk.credential-store encrypted
+    $ hk creds-migrate
+    Enter credentials passphrase: 
+    Moved credentials for default, work to encrypted store.
+`,
+}
+
+func runCredsMigrate(cmd *Command, args []string) {
+	if len(args) != 0 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	kind := credStoreKind()
+	if kind == "" || kind == hkclient.NetRcStore {
+		printFatal("No credential store configured. Set hk.credential-store with `git config`.")
+	}
+	loadNetrc()
+	store := loadCreds()
+
+	var moved []string
+	for _, p := range mustLoadAllProfiles() {
+		u, err := url.Parse(profileAPIURL(p))
+		if err != nil {
+			printFatal("invalid API URL for profile %s: %s", p.Name, err)
+		}
+		machine := p.Machine(u.Host)
+		m := nrc.FindMachine(machine)
+		if m == nil || m.IsDefault() {
+			continue
+		}
+		if err := store.SaveCreds(machine, m.Login, m.Password); err != nil {
+			printFatal("saving credentials for profile %s: %s", p.Name, err)
+		}
+		if err := nrc.RemoveCreds(machine); err != nil {
+			printFatal("removing credentials for profile %s from netrc: %s", p.Name, err)
+		}
+		moved = append(moved, p.Name)
+	}
+	if len(moved) == 0 {
+		log.Println("No credentials to move.")
+		return
+	}
+	log.Printf("Moved credentials for %s to %s store.", strings.Join(moved, ", "), kind)
+}
+
 var cmdLogin = &Command{
 	Run:      runLogin,
-	Usage:    "login",
+	Usage:    "login [-P <profile>] [--api-url <url>] [--org <org>] [--postgresql-host <host>]",
 	Category: "hk",
 	Short:    "log in to your Heroku account" + extra,
 	Long: `
@@ -98,29 +156,84 @@ Log in with your Heroku credentials. Input is accepted by typing
 on the terminal. On unix machines, you can also pipe a password
 on standard input.
 
-Example:
+Credentials are saved for the selected profile, or the default
+profile if none is selected. Logging in to a new profile creates
+it. The remaining options change the profile's settings, and are
+remembered for later commands using the profile.
+
+Options:
+
+    -P, --profile <profile>   log in to the named profile
+    --api-url <url>           Heroku API URL for the profile
+    --org <org>               default organization for the profile
+    --postgresql-host <host>  Heroku Postgres API host for the profile
+
+Examples:
 
     $ hk login
     Enter email: user@test.com
     Enter password: 
-    Login successful.
+    Logged in.
+
+    $ hk login --profile work --org acme
+    Enter email: me@acme.com
+    Enter password: 
+    Logged in.
 `,
 }
 
+var (
+	flagLoginAPIURL string
+	flagLoginOrg    string
+	flagLoginPgHost string
+)
+
+func init() {
+	cmdLogin.Flag.StringVarP(&flagProfile, "profile", "P", "", "profile name")
+	cmdLogin.Flag.StringVar(&flagLoginAPIURL, "api-url", "", "Heroku API URL")
+	cmdLogin.Flag.StringVar(&flagLoginOrg, "org", "", "default organization")
+	cmdLogin.Flag.StringVar(&flagLoginPgHost, "postgresql-host", "", "Heroku Postgres API host")
+}
+
 func runLogin(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
+	}
+
+	// the profile may have been given after the command name, and its
+	// settings may change, so set up the clients again before logging in
+	p, err := hkclient.LoadProfile(profileName())
+	if err != nil && err != hkclient.ErrProfileNotFound {
+		printFatal("loading profiles: " + err.Error())
+	}
+	saveProfile := err == hkclient.ErrProfileNotFound
+	if flagLoginAPIURL != "" {
+		p.APIURL = flagLoginAPIURL
+		saveProfile = true
+	}
+	if flagLoginOrg != "" {
+		p.Org = flagLoginOrg
+		saveProfile = true
+	}
+	if flagLoginPgHost != "" {
+		p.PostgresqlHost = flagLoginPgHost
+		saveProfile = true
 	}
+	initClientsForProfile(p)
 
-	oldEmail := client.Username
+	mustBeInteractive("an email and password to log in", "")
+	var oldEmail string
+	if u, err := url.Parse(apiURL); err == nil {
+		oldEmail, _, _ = hkclient.ProfileCreds(loadCreds(), u, profile)
+	}
 	var email string
 	if oldEmail == "" {
 		fmt.Printf("Enter email: ")
 	} else {
 		fmt.Printf("Enter email [%s]: ", oldEmail)
 	}
-	_, err := fmt.Scanln(&email)
+	_, err = fmt.Scanln(&email)
 	switch {
 	case err != nil && err.Error() != "unexpected newline":
 		printFatal(err.Error())
@@ -144,6 +257,7 @@ func runLogin(cmd *Command, args []string) {
 	if err != nil {
 		if herror, ok := err.(heroku.Error); ok && herror.Id == "two_factor" {
 			// 2FA requested, attempt 2FA login
+			mustBeInteractive("a two-factor auth code", "")
 			var twoFactorCode string
 			fmt.Printf("Enter two-factor auth code: ")
 			if _, err := fmt.Scanln(&twoFactorCode); err != nil {
@@ -156,15 +270,15 @@ func runLogin(cmd *Command, args []string) {
 		}
 	}
 
-	nrc, err = hkclient.LoadNetRc()
-	if err != nil {
-		printFatal("loading netrc: " + err.Error())
-	}
-
-	err = nrc.SaveCreds(address, email, token)
+	err = saveCreds(p.Machine(address), email, token)
 	if err != nil {
 		printFatal("saving new token: " + err.Error())
 	}
+	if saveProfile {
+		if err := hkclient.SaveProfile(p); err != nil {
+			printFatal("saving profile: " + err.Error())
+		}
+	}
 	fmt.Println("Logged in.")
 }
 
@@ -212,7 +326,7 @@ var cmdLogout = &Command{
 	Short:    "log out of your Heroku account" + extra,
 	Long: `
 Log out of your Heroku account and remove credentials from
-this machine.
+this machine. Only the selected profile is logged out.
 
 Example:
 
@@ -224,21 +338,16 @@ Example:
 func runLogout(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	u, err := url.Parse(client.URL)
 	if err != nil {
 		printFatal("couldn't parse client URL: " + err.Error())
 	}
 
-	nrc, err = hkclient.LoadNetRc()
-	if err != nil {
-		printError(err.Error())
-	}
-
-	err = removeCreds(strings.Split(u.Host, ":")[0])
+	err = removeCreds(profile.Machine(strings.Split(u.Host, ":")[0]))
 	if err != nil {
-		printFatal("saving new netrc: " + err.Error())
+		printFatal("removing credentials: " + err.Error())
 	}
 	fmt.Println("Logged out.")
 }
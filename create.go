@@ -19,6 +19,7 @@ Options:
 
     -r <region>  Heroku region to create app in
     -o <org>     name of Heroku organization to create app in
+                 (defaults to the profile's org, if any)
     <name>       optional name for the app
 
 Examples:
@@ -51,11 +52,12 @@ func runCreate(cmd *Command, args []string) {
 	if appname != "" {
 		opts.Name = &appname
 	}
-	if flagOrgName == "personal" { // "personal" means "no org"
+	org := orgName()
+	if org == "personal" { // "personal" means "no org"
 		personal := true
 		opts.Personal = &personal
-	} else if flagOrgName != "" {
-		opts.Organization = &flagOrgName
+	} else if org != "" {
+		opts.Organization = &org
 	}
 	if flagRegion != "" {
 		opts.Region = &flagRegion
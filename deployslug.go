@@ -0,0 +1,283 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/term"
+)
+
+var (
+	flagSlugProcfile string
+	flagSlugCommit   string
+)
+
+var cmdDeploySlug = &Command{
+	Run:      runDeploySlug,
+	Usage:    "deploy-slug [--procfile <file>] [--commit <sha>] <dir>",
+	NeedsApp: true,
+	Category: "release",
+	Short:    "deploy a directory as a slug" + extra,
+	Long: `
+Deploy-slug deploys a directory that has already been built, such
+as the output of a CI job, without pushing to git. It packs the
+directory into a slug, uploads it, and releases it.
+
+The directory becomes /app on the dynos, so it must contain
+everything the app needs to run, including its language runtime.
+Process types are read from the Procfile in the directory.
+
+Options:
+
+    --procfile <file>  read process types from the given file
+                       instead of <dir>/Procfile
+    --commit <sha>     record the git commit the slug was built from
+
+Examples:
+
+    $ hk deploy-slug --commit 3f2a1c9 build/
+    Uploading slug... 100% (24.1 MB)
+    Released build/ to myapp as v43.
+`,
+}
+
+func init() {
+	cmdDeploySlug.Flag.StringVar(&flagSlugProcfile, "procfile", "", "path to Procfile")
+	cmdDeploySlug.Flag.StringVar(&flagSlugCommit, "commit", "", "git commit SHA")
+}
+
+func runDeploySlug(cmd *Command, args []string) {
+	appname := mustApp()
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	dir := args[0]
+	procfile := flagSlugProcfile
+	if procfile == "" {
+		procfile = filepath.Join(dir, "Procfile")
+	}
+	f, err := os.Open(procfile)
+	must(err)
+	processTypes, err := parseProcfile(f)
+	f.Close()
+	must(err)
+	if len(processTypes) == 0 {
+		printFatal("%s has no process types.", procfile)
+	}
+
+	rel, err := deploySlug(appname, dir, processTypes)
+	must(err)
+	log.Printf("Released %s to %s as v%d.", dir, appname, rel.Version)
+}
+
+// deploySlug packs dir into a slug, uploads it and releases it. The slug
+// archive is removed before deploySlug returns, even if it fails.
+func deploySlug(appname, dir string, processTypes map[string]string) (*heroku.Release, error) {
+	archive, err := ioutil.TempFile("", "hk-slug-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	if err := writeSlug(archive, dir); err != nil {
+		return nil, err
+	}
+
+	var opts heroku.SlugCreateOpts
+	if flagSlugCommit != "" {
+		opts.Commit = &flagSlugCommit
+	}
+	slug, err := client.SlugCreate(appname, processTypes, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	method, url := slug.Blob.Method, slug.Blob.URL
+	if u := os.Getenv("HKSLUGURL"); u != "" {
+		url = u
+	}
+	if err := uploadSlug(client.HTTP, method, url, archive, os.Stderr); err != nil {
+		return nil, err
+	}
+	return client.ReleaseCreate(appname, slug.Id, nil)
+}
+
+// parseProcfile reads the process types from a Procfile, which has lines
+// of the form "<type>: <command>".
+func parseProcfile(r io.Reader) (map[string]string, error) {
+	processTypes := make(map[string]string)
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("Procfile line %d: expected <type>: <command>", n)
+		}
+		processTypes[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return processTypes, s.Err()
+}
+
+// writeSlug writes the files in dir to w as a slug: a gzipped tarball whose
+// paths are under ./app. The .git directory is left out.
+func writeSlug(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() && fi.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		name := "./app"
+		if rel != "." {
+			name += "/" + filepath.ToSlash(rel)
+		}
+
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// uploadSlug uploads the slug archive f to the blob URL with hc, streaming
+// it from disk. Progress is written to progress if it's a terminal.
+func uploadSlug(hc *http.Client, method, url string, f *os.File, progress *os.File) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	var pr *progressReader
+	if progress != nil && term.IsTerminal(progress) {
+		pr = &progressReader{r: f, w: progress, total: fi.Size()}
+		defer pr.Done()
+	}
+	// the body is read again from the start if the upload is retried
+	getBody := func() (io.ReadCloser, error) {
+		if _, err := f.Seek(0, 0); err != nil {
+			return nil, err
+		}
+		if pr != nil {
+			pr.n = 0
+			return ioutil.NopCloser(pr), nil
+		}
+		return ioutil.NopCloser(f), nil
+	}
+	body, err := getBody()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = fi.Size()
+	req.GetBody = getBody
+	res, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("uploading slug: %s", res.Status)
+	}
+	return nil
+}
+
+// A progressReader reports how much of a stream of known size has been
+// read, redrawing a single line at most a few times a second.
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+	n     int64
+	last  time.Time
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.n += int64(n)
+	if now := time.Now(); now.Sub(pr.last) > 200*time.Millisecond {
+		pr.last = now
+		pr.print()
+	}
+	return n, err
+}
+
+func (pr *progressReader) print() {
+	pct := int64(100)
+	if pr.total > 0 {
+		pct = pr.n * 100 / pr.total
+	}
+	fmt.Fprintf(pr.w, "\rUploading slug... %d%% (%s)", pct, formatBytes(pr.total))
+}
+
+// Done prints the final progress and ends the line.
+func (pr *progressReader) Done() {
+	pr.print()
+	fmt.Fprintln(pr.w)
+}
+
+// formatBytes formats a size in bytes, e.g. "24.1 MB".
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/heroku/hk/hkclient"
+)
+
+func TestParseProcfile(t *testing.T) {
+	procfile := "web: bin/web -p $PORT\n\n# comment\nworker:bin/worker\n"
+	got, err := parseProcfile(strings.NewReader(procfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"web": "bin/web -p $PORT", "worker": "bin/worker"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := parseProcfile(strings.NewReader("web\n")); err == nil {
+		t.Error("expected an error for a line without a command")
+	}
+}
+
+func TestWriteSlug(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hk-slug-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "Procfile"), []byte("web: bin/web\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "bin", "web"), []byte("#!/bin/sh\n"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0644)
+
+	f, err := ioutil.TempFile("", "hk-slug-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := writeSlug(f, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Seek(0, 0)
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "./app/bin/web" && hdr.Mode&0111 == 0 {
+			t.Errorf("%s isn't executable", hdr.Name)
+		}
+	}
+	sort.Strings(names)
+	want := []string{"./app/", "./app/Procfile", "./app/bin/", "./app/bin/web"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+}
+
+func TestUploadSlug(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		got = r.Method + " " + string(b)
+		if r.ContentLength != int64(len(b)) {
+			t.Errorf("Content-Length = %d, want %d", r.ContentLength, len(b))
+		}
+	}))
+	defer srv.Close()
+
+	f, err := ioutil.TempFile("", "hk-slug-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	f.WriteString("slug")
+
+	if err := uploadSlug(http.DefaultClient, "PUT", srv.URL, f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "PUT slug"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer denied.Close()
+	if err := uploadSlug(http.DefaultClient, "PUT", denied.URL, f, nil); err == nil {
+		t.Error("expected an error for a 403 response")
+	}
+}
+
+func TestUploadSlugRetry(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	f, err := ioutil.TempFile("", "hk-slug-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	f.WriteString("slug")
+
+	hc := &http.Client{Transport: hkclient.NewRetryTransport(http.DefaultTransport)}
+	if err := uploadSlug(hc, "PUT", srv.URL, f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"slug", "slug"}; !reflect.DeepEqual(bodies, want) {
+		t.Errorf("got bodies %q, want %q", bodies, want)
+	}
+}
@@ -3,19 +3,24 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 	"os/exec"
 )
 
 var cmdDestroy = &Command{
 	Run:      runDestroy,
-	Usage:    "destroy <name>",
+	Plan:     planDestroy,
+	Usage:    "destroy [--confirm <name>] [--dry-run] <name>",
 	Category: "app",
 	Short:    "destroy an app",
 	Long: `
 Destroy destroys a heroku app. There is no going back, so be
 sure you mean it. The command will prompt for confirmation, or
-accept confirmation via stdin.
+accept confirmation via stdin or the --confirm flag.
+
+Options:
+
+    --confirm <name>  confirm destroying the app without a prompt
+    --dry-run         show the changes without making them
 
 Example:
 
@@ -25,18 +30,21 @@ Example:
 
     $ echo myapp | hk destroy myapp
     Destroyed myapp.
+
+    $ hk destroy --confirm myapp myapp
+    Destroyed myapp.
 `,
 }
 
+func init() {
+	cmdDestroy.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+}
+
 func runDestroy(cmd *Command, args []string) {
-	if len(args) != 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
-	}
-	appname := args[0]
+	appname := mustParseDestroyArgs(cmd, args)
 
 	warning := fmt.Sprintf("This will destroy %s and its add-ons. Please type %q to continue:", appname, appname)
-	mustConfirm(warning, appname)
+	mustConfirm(appname, warning, appname)
 
 	must(client.AppDelete(appname))
 	log.Printf("Destroyed %s.", appname)
@@ -47,3 +55,27 @@ func runDestroy(cmd *Command, args []string) {
 		}
 	}
 }
+
+func planDestroy(cmd *Command, args []string) *plan {
+	appname := mustParseDestroyArgs(cmd, args)
+	app, err := client.AppInfo(appname)
+	must(err)
+	addons, err := client.AddonList(appname, nil)
+	must(err)
+
+	p := newPlan(appname)
+	p.Change("app", app.Name, "")
+	for _, a := range addons {
+		p.Change(a.Name, a.Plan.Name, "")
+	}
+	p.Request("DELETE", "/apps/"+appname, nil)
+	return p
+}
+
+func mustParseDestroyArgs(cmd *Command, args []string) (appname string) {
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	return args[0]
+}
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"fmt"
 	"log"
-	"os"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -14,6 +11,7 @@ var cmdDomains = &Command{
 	Usage:    "domains",
 	NeedsApp: true,
 	Category: "domain",
+	Raw:      true,
 	Short:    "list domains",
 	Long: `
 Lists domains.
@@ -27,13 +25,13 @@ Examples:
 }
 
 func runDomains(cmd *Command, args []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	appname := mustApp()
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	domains, err := client.DomainList(appname, &heroku.ListRange{
 		Field: "hostname",
@@ -42,13 +40,14 @@ func runDomains(cmd *Command, args []string) {
 	must(err)
 
 	for _, d := range domains {
-		fmt.Fprintln(w, d.Hostname)
+		w.Rec(d, d.Hostname)
 	}
 }
 
 var cmdDomainAdd = &Command{
 	Run:      runDomainAdd,
-	Usage:    "domain-add <domain>",
+	Plan:     planDomainAdd,
+	Usage:    "domain-add [--dry-run] <domain>",
 	NeedsApp: true,
 	Category: "domain",
 	Short:    "add a domain",
@@ -56,19 +55,26 @@ var cmdDomainAdd = &Command{
 
 func runDomainAdd(cmd *Command, args []string) {
 	appname := mustApp()
-	if len(args) != 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
-	}
-	domain := args[0]
+	domain := mustParseDomainArgs(cmd, args)
 	_, err := client.DomainCreate(appname, domain)
 	must(err)
 	log.Printf("Added %s to %s.", domain, appname)
 }
 
+func planDomainAdd(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	domain := mustParseDomainArgs(cmd, args)
+
+	p := newPlan(appname)
+	p.Change("domain", "", domain)
+	p.Request("POST", "/apps/"+appname+"/domains", map[string]string{"hostname": domain})
+	return p
+}
+
 var cmdDomainRemove = &Command{
 	Run:      runDomainRemove,
-	Usage:    "domain-remove <domain>",
+	Plan:     planDomainRemove,
+	Usage:    "domain-remove [--dry-run] <domain>",
 	NeedsApp: true,
 	Category: "domain",
 	Short:    "remove a domain",
@@ -76,11 +82,27 @@ var cmdDomainRemove = &Command{
 
 func runDomainRemove(cmd *Command, args []string) {
 	appname := mustApp()
+	domain := mustParseDomainArgs(cmd, args)
+	must(client.DomainDelete(appname, domain))
+	log.Printf("Removed %s from %s.", domain, appname)
+}
+
+func planDomainRemove(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	domain := mustParseDomainArgs(cmd, args)
+	d, err := client.DomainInfo(appname, domain)
+	must(err)
+
+	p := newPlan(appname)
+	p.Change("domain", d.Hostname, "")
+	p.Request("DELETE", "/apps/"+appname+"/domains/"+domain, nil)
+	return p
+}
+
+func mustParseDomainArgs(cmd *Command, args []string) (domain string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	domain := args[0]
-	must(client.DomainDelete(appname, domain))
-	log.Printf("Removed %s from %s.", domain, appname)
+	return args[0]
 }
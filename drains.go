@@ -3,8 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -14,6 +12,7 @@ var cmdDrains = &Command{
 	Usage:    "drains",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "list log drains" + extra,
 	Long: `
 Lists log drains on an app. Shows the drain's ID, as well as its
@@ -31,7 +30,7 @@ Example:
 func runDrains(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 
@@ -68,11 +67,11 @@ func runDrains(cmd *Command, args []string) {
 		}
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	for _, m := range merged {
-		listRec(w, m.drain.Id, m.addonNameOrURL())
+		w.Rec(m.drain, m.drain.Id, m.addonNameOrURL())
 	}
 }
 
@@ -112,6 +111,7 @@ var cmdDrainInfo = &Command{
 	Usage:    "drain-info <id or url>",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "show info for a log drain" + extra,
 	Long: `
 Shows detailed info for a log drain.
@@ -132,12 +132,15 @@ Example:
 func runDrainInfo(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	drainIdOrURL := args[0]
 	drain, err := client.LogDrainInfo(appname, drainIdOrURL)
 	must(err)
+	if printRaw(drain) {
+		return
+	}
 
 	addonName := "none"
 	if drain.Addon != nil {
@@ -174,7 +177,7 @@ Example:
 func runDrainAdd(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	url := args[0]
@@ -205,7 +208,7 @@ Example:
 func runDrainRemove(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	drainId := args[0]
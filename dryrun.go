@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/mgutz/ansi"
+)
+
+// flagDryRun is set by the --dry-run flag, which is added to every command.
+var flagDryRun bool
+
+// A plan describes the changes a command would make, for --dry-run.
+// Commands that change an app set Command.Plan to a function that fetches
+// the app's current state and returns a plan, without changing anything.
+type plan struct {
+	App      string        `json:"app"`
+	Changes  []planChange  `json:"changes"`
+	Requests []planRequest `json:"requests"`
+}
+
+// A planChange is the before and after value of one setting, e.g. the
+// formation of a process type or a config var. An empty Before or After
+// means the setting is absent.
+type planChange struct {
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// A planRequest is an API request the command would make.
+type planRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+func newPlan(appname string) *plan {
+	return &plan{App: appname, Changes: []planChange{}, Requests: []planRequest{}}
+}
+
+// Change records a change to name, unless before and after are the same.
+func (p *plan) Change(name, before, after string) {
+	if before != after {
+		p.Changes = append(p.Changes, planChange{name, before, after})
+	}
+}
+
+func (p *plan) Request(method, path string, body interface{}) {
+	p.Requests = append(p.Requests, planRequest{method, path, body})
+}
+
+// Print prints the changes as a diff, followed by the API requests.
+func (p *plan) Print() {
+	if printRaw(p) {
+		return
+	}
+	if len(p.Changes) == 0 {
+		fmt.Printf("No changes to %s.\n", p.App)
+	} else {
+		fmt.Printf("Changes to %s:\n", p.App)
+		p.printChanges(os.Stdout)
+	}
+	fmt.Println()
+	fmt.Println("Requests:")
+	for _, r := range p.Requests {
+		line := r.Method + " " + r.Path
+		if r.Body != nil {
+			b, err := json.Marshal(r.Body)
+			must(err)
+			line += " " + string(b)
+		}
+		fmt.Println("  " + line)
+	}
+}
+
+// printChanges prints each change as a red line with the value before and a
+// green line with the value after.
+func (p *plan) printChanges(w io.Writer) {
+	for _, c := range p.Changes {
+		if c.Before != "" {
+			fmt.Fprintln(w, ansi.Color("- "+c.Name+"="+c.Before, "red")+ansi.ColorCode("reset"))
+		}
+		if c.After != "" {
+			fmt.Fprintln(w, ansi.Color("+ "+c.Name+"="+c.After, "green")+ansi.ColorCode("reset"))
+		}
+	}
+}
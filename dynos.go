@@ -2,12 +2,11 @@ package main
 
 import (
 	"encoding/json"
-	"io"
-	"os"
+	"fmt"
+	"log"
 	"sort"
 	"strconv"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
@@ -15,13 +14,27 @@ import (
 
 var cmdDynos = &Command{
 	Run:      runDynos,
-	Usage:    "dynos [<name>...]",
+	Usage:    "dynos [--limit <n>] [--watch [--interval <duration>]] [<name>...]",
 	NeedsApp: true,
 	Category: "dyno",
+	Raw:      true,
 	Short:    "list dynos",
 	Long: `
 Lists dynos. Shows the name, size, state, age, and command.
 
+With --watch, hk keeps listing the dynos, redrawing the list in
+place. Dynos that changed state since the last check are
+highlighted, along with the state they changed from, and the
+number of times each dyno restarted since the watch began is
+shown. When interrupted, hk prints a summary of the changes.
+
+Options:
+
+    --limit <n>            list at most n dynos, in order of name
+    --watch                keep listing the dynos until interrupted
+    --interval <duration>  how often to check the dynos, with --watch
+                           (default 5s)
+
 Examples:
 
     $ hk dynos
@@ -32,50 +45,78 @@ Examples:
     $ hk dynos web
     web.1     1X  up  15h  "blog /app /tmp/dst"
     web.2     1X  up   8h  "blog /app /tmp/dst"
+
+    $ hk dynos --watch web
+    myapp dynos, every 5s, Jun 14 09:40:05
+
+    web.1  1X  starting → up  3s  1 restart  "blog /app /tmp/dst"
+    web.2  1X  up             8h             "blog /app /tmp/dst"
+    ^C
+    Watched myapp for 2m10s.
+    State changes:
+      09:39:55  web.1  up → starting
+      09:40:05  web.1  starting → up
+    Restarts: web.1 (1)
 `,
 }
 
-func runDynos(cmd *Command, names []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
-	defer w.Flush()
+func init() {
+	cmdDynos.Flag.IntVar(&flagLimit, "limit", 0, "maximum number of dynos to list")
+	cmdDynos.Flag.BoolVar(&flagDynosWatch, "watch", false, "keep listing dynos")
+	cmdDynos.Flag.DurationVar(&flagDynosInterval, "interval", 5*time.Second, "how often to check dynos")
+}
 
-	if len(names) > 1 {
+func runDynos(cmd *Command, names []string) {
+	if len(names) > 1 || flagDynosInterval <= 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
+	}
+	if flagDynosWatch {
+		watchDynos(names)
+		return
 	}
+
+	w := newListWriter()
+	defer w.Flush()
 	listDynos(w, names)
 }
 
-func listDynos(w io.Writer, names []string) {
+func listDynos(w *listWriter, names []string) {
 	appname := mustApp()
 	dynos, err := client.DynoList(appname, nil)
 	must(err)
 	sort.Sort(DynosByName(dynos))
+	dynos = filterDynos(dynos, names)
+	for _, d := range dynos[:limit(len(dynos))] {
+		listDyno(w, &d)
+	}
+}
 
+// filterDynos returns the dynos with the given names, or of the given process
+// types. All dynos are returned if there are no names.
+func filterDynos(dynos []heroku.Dyno, names []string) []heroku.Dyno {
 	if len(names) == 0 {
-		for _, d := range dynos {
-			listDyno(w, &d)
-		}
-		return
+		return dynos
 	}
-
+	var matched []heroku.Dyno
 	for _, name := range names {
 		for _, d := range dynos {
 			if !strings.Contains(name, ".") {
 				if strings.HasPrefix(d.Name, name+".") {
-					listDyno(w, &d)
+					matched = append(matched, d)
 				}
 			} else {
 				if d.Name == name {
-					listDyno(w, &d)
+					matched = append(matched, d)
 				}
 			}
 		}
 	}
+	return matched
 }
 
-func listDyno(w io.Writer, d *heroku.Dyno) {
-	listRec(w,
+func listDyno(w *listWriter, d *heroku.Dyno) {
+	w.Rec(*d,
 		d.Name,
 		d.Size,
 		d.State,
@@ -118,3 +159,71 @@ func dynoSeq(d *heroku.Dyno) int {
 	i, _ := strconv.Atoi(strings.TrimPrefix(d.Name, d.Type+"."))
 	return i
 }
+
+const (
+	// dynoPollInterval is how often hk polls an app's dynos while waiting
+	// for them to change state.
+	dynoPollInterval = 2 * time.Second
+
+	// releaseWaitTimeout is how long waitForRelease waits for an app's
+	// dynos to be up on a new release.
+	releaseWaitTimeout = 5 * time.Minute
+
+	// dynoRestartTimeout is how long a rolling restart waits for a
+	// restarted dyno to be up again.
+	dynoRestartTimeout = 5 * time.Minute
+)
+
+// waitForRelease polls an app's dynos until they're all up on the given
+// release, then keeps watching them for the grace period. It returns an
+// error if any of them crash on the release, or if they aren't all up
+// within releaseWaitTimeout.
+func waitForRelease(appname string, version int, grace time.Duration) error {
+	deadline := time.Now().Add(releaseWaitTimeout)
+	var upSince time.Time
+	for {
+		dynos, err := client.DynoList(appname, nil)
+		if err != nil {
+			return err
+		}
+		up, crashed := releaseDynoStates(dynos, version)
+		if len(crashed) > 0 {
+			return fmt.Errorf("%s crashed on v%d", strings.Join(crashed, ", "), version)
+		}
+		now := time.Now()
+		switch {
+		case !up:
+			upSince = time.Time{}
+			if now.After(deadline) {
+				return fmt.Errorf("dynos weren't all up on v%d after %s", version, releaseWaitTimeout)
+			}
+		case upSince.IsZero():
+			upSince = now
+			log.Printf("All dynos are up on v%d; watching them for %s.", version, grace)
+		}
+		if !upSince.IsZero() && now.Sub(upSince) >= grace {
+			return nil
+		}
+		time.Sleep(dynoPollInterval)
+	}
+}
+
+// releaseDynoStates reports whether all of an app's dynos, other than
+// one-off dynos, are up on the given release, and returns the names of those
+// that crashed on it.
+func releaseDynoStates(dynos []heroku.Dyno, version int) (up bool, crashed []string) {
+	up = true
+	for _, d := range dynos {
+		if d.Type == "run" {
+			continue
+		}
+		if d.Release.Version == version && d.State == "crashed" {
+			crashed = append(crashed, d.Name)
+		}
+		if d.Release.Version != version || d.State != "up" {
+			up = false
+		}
+	}
+	sort.Strings(crashed)
+	return up, crashed
+}
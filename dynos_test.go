@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func newTestDyno(name, state string, version int) heroku.Dyno {
+	d := heroku.Dyno{Name: name, Type: name[:len(name)-2], State: state}
+	d.Release.Version = version
+	return d
+}
+
+func TestReleaseDynoStates(t *testing.T) {
+	tests := []struct {
+		dynos   []heroku.Dyno
+		up      bool
+		crashed []string
+	}{
+		{
+			[]heroku.Dyno{newTestDyno("web.1", "up", 7), newTestDyno("web.2", "up", 7)},
+			true, nil,
+		},
+		{
+			[]heroku.Dyno{newTestDyno("web.1", "up", 7), newTestDyno("web.2", "starting", 7)},
+			false, nil,
+		},
+		{
+			[]heroku.Dyno{newTestDyno("web.1", "up", 6), newTestDyno("run.1", "up", 6)},
+			false, nil,
+		},
+		{
+			[]heroku.Dyno{newTestDyno("web.1", "up", 7), newTestDyno("run.1", "up", 6)},
+			true, nil,
+		},
+		{
+			[]heroku.Dyno{newTestDyno("web.2", "crashed", 7), newTestDyno("web.1", "crashed", 6)},
+			false, []string{"web.2"},
+		},
+	}
+	for i, tt := range tests {
+		up, crashed := releaseDynoStates(tt.dynos, 7)
+		if up != tt.up || !reflect.DeepEqual(crashed, tt.crashed) {
+			t.Errorf("test %d: got %v, %q, want %v, %q", i, up, crashed, tt.up, tt.crashed)
+		}
+	}
+}
+
+func TestDynoWatchUpdate(t *testing.T) {
+	start := time.Date(2014, 6, 14, 9, 40, 0, 0, time.UTC)
+	dw := newDynoWatch(start)
+	web1 := newTestDyno("web.1", "up", 7)
+	web2 := newTestDyno("web.2", "up", 7)
+	dw.update([]heroku.Dyno{web1, web2}, start)
+	if len(dw.events) != 0 || len(dw.changed) != 0 {
+		t.Fatalf("first poll: events %v, changed %v, want none", dw.events, dw.changed)
+	}
+
+	// web.1 crashes, web.2 restarts between polls, web.3 appears
+	web1.State = "crashed"
+	web2.UpdatedAt = start.Add(time.Minute)
+	web3 := newTestDyno("web.3", "starting", 7)
+	dw.update([]heroku.Dyno{web1, web2, web3}, start.Add(time.Minute))
+
+	// web.1 restarts, web.3 goes away
+	web1.State = "starting"
+	dw.update([]heroku.Dyno{web1, web2}, start.Add(2*time.Minute))
+
+	var events []string
+	for _, e := range dw.events {
+		events = append(events, e.Dyno+":"+e.From+">"+e.To)
+	}
+	want := []string{"web.1:up>crashed", "web.3:>starting", "web.1:crashed>starting", "web.3:starting>"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+	if want := map[string]int{"web.1": 1, "web.2": 1}; !reflect.DeepEqual(dw.restarts, want) {
+		t.Errorf("restarts = %v, want %v", dw.restarts, want)
+	}
+	if want := map[string]string{"web.1": "crashed"}; !reflect.DeepEqual(dw.changed, want) {
+		t.Errorf("changed = %v, want %v", dw.changed, want)
+	}
+}
+
+func TestDynoWatchDraw(t *testing.T) {
+	now := time.Date(2014, 6, 14, 9, 40, 0, 0, time.UTC)
+	dw := newDynoWatch(now)
+
+	// scaled to 0, or no dynos matching the names given
+	var buf bytes.Buffer
+	dw.update(nil, now)
+	dw.draw(&buf, "myapp", nil, now, true, 10)
+	if !strings.HasSuffix(buf.String(), "\nNo dynos.\n") {
+		t.Errorf("with no dynos, drew %q", buf.String())
+	}
+
+	var dynos []heroku.Dyno
+	for _, name := range []string{"web.1", "web.2", "web.3", "web.4"} {
+		dynos = append(dynos, newTestDyno(name, "up", 7))
+	}
+	buf.Reset()
+	dw.update(dynos, now)
+	dw.draw(&buf, "myapp", dynos, now, false, 3)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[2], "web.1 ") || lines[4] != "... and 2 more" {
+		t.Errorf("with 3 rows, drew %q", lines)
+	}
+}
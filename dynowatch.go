@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/mgutz/ansi"
+	"github.com/heroku/hk/term"
+)
+
+var (
+	flagDynosWatch    bool
+	flagDynosInterval time.Duration
+)
+
+// A dynoEvent is a change in a dyno's state seen while watching. An empty
+// From means the dyno appeared, and an empty To that it went away.
+type dynoEvent struct {
+	At   time.Time
+	Dyno string
+	From string
+	To   string
+}
+
+// A dynoWatch tracks an app's dynos across polls, for hk dynos --watch.
+type dynoWatch struct {
+	start    time.Time
+	polls    int
+	states   map[string]string
+	updated  map[string]time.Time
+	restarts map[string]int
+	events   []dynoEvent
+
+	// changed holds the previous state of each dyno that changed state in
+	// the latest poll.
+	changed map[string]string
+}
+
+func newDynoWatch(start time.Time) *dynoWatch {
+	return &dynoWatch{
+		start:    start,
+		states:   make(map[string]string),
+		updated:  make(map[string]time.Time),
+		restarts: make(map[string]int),
+		changed:  make(map[string]string),
+	}
+}
+
+// update records the dynos from a poll made at the given time.
+func (dw *dynoWatch) update(dynos []heroku.Dyno, now time.Time) {
+	dw.changed = make(map[string]string)
+	seen := make(map[string]bool)
+	for _, d := range dynos {
+		seen[d.Name] = true
+		prev, known := dw.states[d.Name]
+		switch {
+		case !known && dw.polls > 0:
+			dw.events = append(dw.events, dynoEvent{now, d.Name, "", d.State})
+			dw.changed[d.Name] = ""
+		case known && prev != d.State:
+			dw.events = append(dw.events, dynoEvent{now, d.Name, prev, d.State})
+			dw.changed[d.Name] = prev
+			if d.State == "starting" || d.State == "restarting" {
+				dw.restarts[d.Name]++
+			}
+		case known && d.State == "up" && d.UpdatedAt.After(dw.updated[d.Name]):
+			// restarted between polls, so only its age shows it
+			dw.restarts[d.Name]++
+			dw.changed[d.Name] = prev
+		}
+		dw.states[d.Name] = d.State
+		dw.updated[d.Name] = d.UpdatedAt
+	}
+	for name, state := range dw.states {
+		if !seen[name] {
+			dw.events = append(dw.events, dynoEvent{now, name, state, ""})
+			delete(dw.states, name)
+			delete(dw.updated, name)
+		}
+	}
+	dw.polls++
+}
+
+// draw writes the table of dynos, coloring the rows of dynos that changed
+// in the latest poll by their new state.
+func (dw *dynoWatch) draw(w io.Writer, appname string, dynos []heroku.Dyno, now time.Time, color bool, maxRows int) {
+	fmt.Fprintf(w, "%s dynos, every %s, %s\n\n", appname, flagDynosInterval, now.Format("Jan _2 15:04:05"))
+	if len(dynos) == 0 {
+		fmt.Fprintln(w, "No dynos.")
+		return
+	}
+
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 1, 2, 2, ' ', 0)
+	for _, d := range dynos {
+		state := d.State
+		if prev, ok := dw.changed[d.Name]; ok && prev != "" && prev != d.State {
+			state = prev + " → " + d.State
+		}
+		restarts := ""
+		if n := dw.restarts[d.Name]; n > 0 {
+			restarts = fmt.Sprintf("%d restarts", n)
+			if n == 1 {
+				restarts = "1 restart"
+			}
+		}
+		listRec(tw, d.Name, d.Size, state, prettyDuration{dynoAge(&d)}, restarts, abbrev(maybeQuote(d.Command), 30))
+	}
+	tw.Flush()
+
+	// one line per dyno, as commands are quoted
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, d := range dynos {
+		if maxRows > 0 && i == maxRows-1 && len(dynos) > maxRows {
+			fmt.Fprintf(w, "... and %d more\n", len(dynos)-i)
+			break
+		}
+		line := lines[i]
+		if _, ok := dw.changed[d.Name]; ok && color {
+			line = ansi.Color(line, dynoStateColor(d.State)) + ansi.ColorCode("reset")
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func dynoStateColor(state string) string {
+	switch state {
+	case "up":
+		return "green"
+	case "crashed":
+		return "red"
+	}
+	return "yellow"
+}
+
+// printSummary prints what happened while watching.
+func (dw *dynoWatch) printSummary(w io.Writer, appname string, now time.Time) {
+	fmt.Fprintf(w, "Watched %s for %s.\n", appname, strings.TrimSpace(prettyDuration{now.Sub(dw.start)}.String()))
+	if len(dw.events) == 0 {
+		fmt.Fprintln(w, "No dynos changed state.")
+		return
+	}
+	fmt.Fprintln(w, "State changes:")
+	for _, e := range dw.events {
+		from, to := e.From, e.To
+		if from == "" {
+			from = "(new)"
+		}
+		if to == "" {
+			to = "(gone)"
+		}
+		fmt.Fprintf(w, "  %s  %s  %s → %s\n", e.At.Format("15:04:05"), e.Dyno, from, to)
+	}
+
+	var names []string
+	for name := range dw.restarts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		var s []string
+		for _, name := range names {
+			s = append(s, fmt.Sprintf("%s (%d)", name, dw.restarts[name]))
+		}
+		fmt.Fprintf(w, "Restarts: %s\n", strings.Join(s, ", "))
+	}
+
+	var crashed []string
+	for name, state := range dw.states {
+		if state == "crashed" {
+			crashed = append(crashed, name)
+		}
+	}
+	sort.Strings(crashed)
+	if len(crashed) > 0 {
+		fmt.Fprintf(w, "Crashed now: %s\n", strings.Join(crashed, ", "))
+	}
+}
+
+// watchDynos polls the app's dynos and redraws them until interrupted, then
+// prints a summary.
+func watchDynos(names []string) {
+	appname := mustApp()
+	ansiOut := term.IsANSI(os.Stdout)
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+
+	dw := newDynoWatch(time.Now())
+	for {
+		dynos, err := client.DynoList(appname, nil)
+		now := time.Now()
+		if err != nil {
+			printWarning("Couldn't list dynos: %s", err)
+		} else {
+			dynos = filterDynos(dynos, names)
+			sort.Sort(DynosByName(dynos))
+			dw.update(dynos, now)
+
+			maxRows := 0
+			if ansiOut {
+				fmt.Print("\033[H\033[2J") // move home and clear the screen
+				if lines, err := term.Lines(); err == nil {
+					maxRows = lines - 3 // header, blank line, cursor
+				}
+			} else {
+				fmt.Println()
+			}
+			dw.draw(os.Stdout, appname, dynos, now, ansiOut, maxRows)
+		}
+
+		select {
+		case <-sigc:
+			fmt.Println()
+			dw.printSummary(os.Stdout, appname, time.Now())
+			return
+		case <-time.After(flagDynosInterval):
+		}
+	}
+}
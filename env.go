@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 	"sort"
 	"strings"
 )
@@ -13,6 +12,7 @@ var cmdEnv = &Command{
 	Usage:    "env",
 	NeedsApp: true,
 	Category: "config",
+	Raw:      true,
 	Short:    "list env vars",
 	Long:     `Show all env vars.`,
 }
@@ -20,10 +20,13 @@ var cmdEnv = &Command{
 func runEnv(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	config, err := client.ConfigVarInfo(mustApp())
 	must(err)
+	if printRaw(config) {
+		return
+	}
 	var configKeys []string
 	for k := range config {
 		configKeys = append(configKeys, k)
@@ -53,7 +56,7 @@ Example:
 func runGet(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	config, err := client.ConfigVarInfo(mustApp())
 	must(err)
@@ -66,25 +69,49 @@ func runGet(cmd *Command, args []string) {
 
 var cmdSet = &Command{
 	Run:      runSet,
-	Usage:    "set <name>=<value>...",
+	Plan:     planSet,
+	Usage:    "set [--dry-run] <name>=<value>...",
 	NeedsApp: true,
 	Category: "config",
 	Short:    "set env var",
 	Long: `
 Set the value of an env var.
 
-Example:
+Options:
+
+    --dry-run  show the changes without making them
+
+Examples:
 
     $ hk set BUILDPACK_URL=http://github.com/kr/heroku-buildpack-inline.git
     Set env vars and restarted myapp.
+
+    $ hk set --dry-run DEBUG=1
+    Changes to myapp:
+    - DEBUG=0
+    + DEBUG=1
+
+    Requests:
+      PATCH /apps/myapp/config-vars {"DEBUG":"1"}
 `,
 }
 
 func runSet(cmd *Command, args []string) {
 	appname := mustApp()
+	config := mustParseSetArgs(cmd, args)
+	_, err := client.ConfigVarUpdate(appname, config)
+	must(err)
+	log.Printf("Set env vars and restarted " + appname + ".")
+}
+
+func planSet(cmd *Command, args []string) *plan {
+	return planConfigVarUpdate(mustApp(), mustParseSetArgs(cmd, args))
+}
+
+func mustParseSetArgs(cmd *Command, args []string) map[string]*string {
 	if len(args) == 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	config := make(map[string]*string)
 	for _, arg := range args {
@@ -95,20 +122,47 @@ func runSet(cmd *Command, args []string) {
 		val := arg[i+1:]
 		config[arg[:i]] = &val
 	}
-	_, err := client.ConfigVarUpdate(appname, config)
+	return config
+}
+
+// planConfigVarUpdate returns a plan for updating appname's config vars,
+// where a nil value unsets a var.
+func planConfigVarUpdate(appname string, config map[string]*string) *plan {
+	current, err := client.ConfigVarInfo(appname)
 	must(err)
-	log.Printf("Set env vars and restarted " + appname + ".")
+
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	p := newPlan(appname)
+	for _, k := range keys {
+		var after string
+		if config[k] != nil {
+			after = *config[k]
+		}
+		p.Change(k, current[k], after)
+	}
+	p.Request("PATCH", "/apps/"+appname+"/config-vars", config)
+	return p
 }
 
 var cmdUnset = &Command{
 	Run:      runUnset,
-	Usage:    "unset <name>...",
+	Plan:     planUnset,
+	Usage:    "unset [--dry-run] <name>...",
 	NeedsApp: true,
 	Category: "config",
 	Short:    "unset env var",
 	Long: `
 Unset an env var.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Example:
 
     $ hk unset BUILDPACK_URL
@@ -118,15 +172,24 @@ Example:
 
 func runUnset(cmd *Command, args []string) {
 	appname := mustApp()
+	config := mustParseUnsetArgs(cmd, args)
+	_, err := client.ConfigVarUpdate(appname, config)
+	must(err)
+	log.Printf("Unset env vars and restarted %s.", appname)
+}
+
+func planUnset(cmd *Command, args []string) *plan {
+	return planConfigVarUpdate(mustApp(), mustParseUnsetArgs(cmd, args))
+}
+
+func mustParseUnsetArgs(cmd *Command, args []string) map[string]*string {
 	if len(args) == 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	config := make(map[string]*string)
 	for _, key := range args {
 		config[key] = nil
 	}
-	_, err := client.ConfigVarUpdate(appname, config)
-	must(err)
-	log.Printf("Unset env vars and restarted %s.", appname)
+	return config
 }
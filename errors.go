@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/postgresql"
+)
+
+// Exit codes, documented in doc/exit-codes.md.
+const (
+	exitError          = 1
+	exitUsage          = 2
+	exitUnavailable    = 75
+	exitForbidden      = 77
+	exitTwoFactor      = 79
+	exitUnauthorized   = 80
+	exitNotFound       = 81
+	exitRateLimit      = 82
+	exitInvalid        = 83
+	exitNonInteractive = 84
+)
+
+// errorInfo is the structured form of an error, printed to stderr when
+// HKERRORS=json.
+type errorInfo struct {
+	Id       string `json:"id,omitempty"`
+	Message  string `json:"message"`
+	URL      string `json:"url,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+func newErrorInfo(err error) *errorInfo {
+	var e errorInfo
+	switch t := err.(type) {
+	case heroku.Error:
+		e = errorInfo{Id: t.Id, Message: t.Message, URL: t.URL, Status: t.StatusCode}
+	case postgresql.Error:
+		e = errorInfo{Id: t.Id, Message: t.Message, URL: t.URL, Status: t.StatusCode}
+	default:
+		e.Message = err.Error()
+	}
+	if e.Message == "" {
+		e.Message = err.Error()
+	}
+	e.ExitCode = apiExitCode(e.Id, e.Status)
+	return &e
+}
+
+// apiExitCode returns the exit code for an API error with the given id and
+// HTTP status. The id takes precedence, since it's more specific.
+func apiExitCode(id string, status int) int {
+	switch id {
+	case "two_factor":
+		return exitTwoFactor
+	case "unauthorized":
+		return exitUnauthorized
+	case "forbidden", "suspended", "delinquent", "verification_required":
+		return exitForbidden
+	case "not_found":
+		return exitNotFound
+	case "rate_limit":
+		return exitRateLimit
+	case "bad_request", "invalid_params", "conflict":
+		return exitInvalid
+	case "unavailable":
+		return exitUnavailable
+	}
+	switch {
+	case status == 401:
+		return exitUnauthorized
+	case status == 402 || status == 403:
+		return exitForbidden
+	case status == 404:
+		return exitNotFound
+	case status == 429:
+		return exitRateLimit
+	case status == 400 || status == 409 || status == 422:
+		return exitInvalid
+	case status/100 == 5:
+		return exitUnavailable
+	}
+	return exitError
+}
+
+func errorsJSON() bool {
+	return os.Getenv("HKERRORS") == "json"
+}
+
+// exitWithError prints err, along with a hint about how to fix it if there
+// is one, and exits with the exit code for err.
+func exitWithError(err error) {
+	e := newErrorInfo(err)
+	if errorsJSON() {
+		printErrorJSON(e)
+	} else {
+		msg := err.Error()
+		switch e.ExitCode {
+		case exitTwoFactor:
+			msg += " Authorize with `hk authorize`."
+		case exitUnauthorized:
+			msg += " Log in with `hk login`."
+		}
+		printError(msg)
+	}
+	exit(e.ExitCode)
+}
+
+func printErrorJSON(e *errorInfo) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintln(os.Stderr, string(b))
+}
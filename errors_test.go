@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/postgresql"
+)
+
+func TestNewErrorInfo(t *testing.T) {
+	var tests = []struct {
+		err  error
+		want errorInfo
+	}{
+		{
+			errors.New("boom"),
+			errorInfo{Message: "boom", ExitCode: exitError},
+		},
+		{
+			heroku.Error{Id: "not_found", Message: "Couldn't find that app.", StatusCode: 404},
+			errorInfo{Id: "not_found", Message: "Couldn't find that app.", Status: 404, ExitCode: exitNotFound},
+		},
+		{
+			heroku.Error{Id: "two_factor", Message: "Two-factor required.", StatusCode: 403},
+			errorInfo{Id: "two_factor", Message: "Two-factor required.", Status: 403, ExitCode: exitTwoFactor},
+		},
+		{
+			heroku.Error{Message: "Unexpected error: Too Many Requests", StatusCode: 429},
+			errorInfo{Message: "Unexpected error: Too Many Requests", Status: 429, ExitCode: exitRateLimit},
+		},
+		{
+			postgresql.Error{Message: "unexpected status code=503", StatusCode: 503},
+			errorInfo{Message: "unexpected status code=503", Status: 503, ExitCode: exitUnavailable},
+		},
+	}
+	for _, tt := range tests {
+		if got := newErrorInfo(tt.err); *got != tt.want {
+			t.Errorf("newErrorInfo(%#v) = %+v, want %+v", tt.err, *got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,7 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"log"
-	"os"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -15,6 +12,7 @@ var cmdFeatures = &Command{
 	Usage:    "features",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "list app features" + extra,
 	Long: `
 Features lists Heroku Labs features for an app.
@@ -31,9 +29,9 @@ Example:
 func runFeatures(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	features, err := client.AppFeatureList(mustApp(), &heroku.ListRange{Field: "name"})
@@ -42,13 +40,13 @@ func runFeatures(cmd *Command, args []string) {
 	listFeatures(w, features)
 }
 
-func listFeatures(w io.Writer, features []heroku.AppFeature) {
+func listFeatures(w *listWriter, features []heroku.AppFeature) {
 	for _, f := range features {
 		enabled := " "
 		if f.Enabled {
 			enabled = "+"
 		}
-		listRec(w,
+		w.Rec(f,
 			enabled,
 			f.Name,
 		)
@@ -60,6 +58,7 @@ var cmdFeatureInfo = &Command{
 	Usage:    "feature-info <feature>",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "show info for an app feature" + extra,
 	Long: `
 Shows detailed info for a Heroku Labs feature on an app.
@@ -74,12 +73,15 @@ Example:
 func runFeatureInfo(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	featureName := args[0]
 	feature, err := client.AppFeatureInfo(appname, featureName)
 	must(err)
+	if printRaw(feature) {
+		return
+	}
 	fmt.Printf("Name:         %s\n", feature.Name)
 	fmt.Printf("Docs:         %s\n", feature.DocURL)
 	fmt.Printf("Enabled:      %t\n", feature.Enabled)
@@ -105,7 +107,7 @@ Example:
 func runFeatureEnable(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	featureName := args[0]
@@ -133,7 +135,7 @@ Example:
 func runFeatureDisable(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	featureName := args[0]
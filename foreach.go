@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	flagForeachMatch       string
+	flagForeachConcurrency int
+)
+
+var cmdForeach = &Command{
+	Run:      runForeach,
+	Usage:    "foreach [-o <org>] [-m <pattern>] [--apps <app>,...] [-c <n>] -- <command> [<args>...]",
+	Category: "app",
+	Short:    "run a command on many apps" + extra,
+	Long: `
+Foreach runs an hk command once for each of several apps, a few
+at a time. Each line of output is prefixed with the app's name.
+If the command fails for any app, foreach prints a summary of the
+failures and exits with status 1.
+
+The command is run non-interactively (see HK_NONINTERACTIVE in
+'hk help environ'), so destructive commands must be confirmed
+with --confirm. If the credentials are encrypted, foreach asks
+for their passphrase once, and passes it on to the commands.
+
+Options:
+
+    -o <org>           use apps in the given organization
+    -m <pattern>       use apps whose names match a glob pattern,
+                       e.g. 'api-*'; may be a comma-separated list
+    --apps <app>,...   use the given apps, instead of listing them
+    -c <n>             run the command on at most n apps at once
+                       (default 8)
+
+Examples:
+
+    $ hk foreach -o acme -m 'api-*' -- get DATABASE_URL
+    api-eu: postgres://...
+    api-us: postgres://...
+
+    $ hk foreach --apps myapp,myapp-staging -- dynos
+    myapp:         web.1  1X  up  15h  bin/web
+    myapp-staging: web.1  1X  up   2d  bin/web
+
+    $ hk foreach -m 'api-*' -- restart web
+    api-eu: Restarted web dynos for api-eu.
+    api-us: error: Couldn't find that process type.
+    Failed on 1 of 2 apps:
+      api-us (exit status 81)
+`,
+}
+
+func init() {
+	cmdForeach.Flag.StringVarP(&flagOrgName, "org", "o", "", "organization name")
+	cmdForeach.Flag.StringVarP(&flagForeachMatch, "match", "m", "", "app name glob patterns")
+	cmdForeach.Flag.StringVar(&flagApps, "apps", "", "comma-separated app names")
+	cmdForeach.Flag.IntVarP(&flagForeachConcurrency, "concurrency", "c", 8, "max apps at once")
+}
+
+func runForeach(cmd *Command, args []string) {
+	if len(args) == 0 || flagForeachConcurrency < 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	var patterns []string
+	if flagForeachMatch != "" {
+		patterns = strings.Split(flagForeachMatch, ",")
+		for _, p := range patterns {
+			if _, err := filepath.Match(p, ""); err != nil {
+				printFatal("bad pattern %q: %s", p, err)
+			}
+		}
+	}
+
+	var names []string
+	if flagApps != "" {
+		names = strings.Split(flagApps, ",")
+	} else {
+		apps, err := getAppList(orgName())
+		must(err)
+		for _, a := range apps {
+			names = append(names, a.Name)
+		}
+	}
+	names = matchAppNames(names, patterns)
+	if len(names) == 0 {
+		printFatal("no apps matched")
+	}
+
+	self, err := os.Executable()
+	must(err)
+
+	// the commands can't ask for the credentials passphrase, so unlock the
+	// credential store now, and pass them the passphrase if one was entered
+	getCreds(apiURL)
+	env := append(os.Environ(), "HKPROFILE="+profile.Name, "HK_NONINTERACTIVE=1")
+	if credsPassphrase != "" {
+		env = append(env, "HKPASSPHRASE="+credsPassphrase)
+	}
+
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	var (
+		mu       sync.Mutex // serializes output and failures
+		failures = make(map[string]error)
+		wg       sync.WaitGroup
+		appc     = make(chan string)
+	)
+	for i := 0; i < flagForeachConcurrency && i < len(names); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for appname := range appc {
+				prefix := fmt.Sprintf("%-*s ", width+1, appname+":")
+				stdout := &prefixWriter{w: os.Stdout, prefix: prefix, mu: &mu}
+				stderr := &prefixWriter{w: os.Stderr, prefix: prefix, mu: &mu}
+
+				c := exec.Command(self, args...)
+				c.Env = append(env[:len(env):len(env)], "HKAPP="+appname)
+				c.Stdout, c.Stderr = stdout, stderr
+				err := c.Run()
+				stdout.Flush()
+				stderr.Flush()
+				if err != nil {
+					mu.Lock()
+					failures[appname] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, name := range names {
+		appc <- name
+	}
+	close(appc)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		failed := make([]string, 0, len(failures))
+		for name := range failures {
+			failed = append(failed, name)
+		}
+		sort.Strings(failed)
+		printError("Failed on %d of %d apps:", len(failures), len(names))
+		for _, name := range failed {
+			fmt.Fprintf(os.Stderr, "  %s (%s)\n", name, failures[name])
+		}
+		exit(1)
+	}
+}
+
+// matchAppNames returns the names that match any of patterns, sorted and
+// without duplicates. All names match if there are no patterns.
+func matchAppNames(names, patterns []string) []string {
+	seen := make(map[string]bool)
+	var matched []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		ok := len(patterns) == 0
+		for _, p := range patterns {
+			if m, _ := filepath.Match(p, name); m {
+				ok = true
+				break
+			}
+		}
+		if ok {
+			seen[name] = true
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// A prefixWriter writes each line written to it to w, preceded by prefix.
+// Whole lines are written while holding mu, so that lines from concurrent
+// writers aren't interleaved.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	pw.buf.Write(p)
+	for {
+		i := bytes.IndexByte(pw.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := pw.buf.Next(i + 1)
+		pw.mu.Lock()
+		_, err := fmt.Fprintf(pw.w, "%s%s", pw.prefix, line)
+		pw.mu.Unlock()
+		if err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes any incomplete last line.
+func (pw *prefixWriter) Flush() {
+	if pw.buf.Len() > 0 {
+		pw.Write([]byte{'\n'})
+	}
+}
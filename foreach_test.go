@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMatchAppNames(t *testing.T) {
+	names := []string{"api-us", "web", "api-eu", "api-us", " worker "}
+	var tests = []struct {
+		patterns []string
+		want     []string
+	}{
+		{nil, []string{"api-eu", "api-us", "web", "worker"}},
+		{[]string{"api-*"}, []string{"api-eu", "api-us"}},
+		{[]string{"web", "w*"}, []string{"web", "worker"}},
+		{[]string{"nope"}, nil},
+	}
+	for _, tt := range tests {
+		if got := matchAppNames(names, tt.patterns); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchAppNames(%q) = %q, want %q", tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw := &prefixWriter{w: &buf, prefix: "myapp: ", mu: new(sync.Mutex)}
+	pw.Write([]byte("one\ntw"))
+	pw.Write([]byte("o\nthree"))
+	pw.Flush()
+	want := "myapp: one\nmyapp: two\nmyapp: three\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var flagForkDatabases bool
+
+var cmdFork = &Command{
+	Run:      runFork,
+	Usage:    "fork [-r <region>] [-o <org>] [--fork-databases] <source> <name>",
+	Category: "app",
+	Short:    "copy an app's setup to a new app" + extra,
+	Long: `
+Fork creates a new app with the same setup as an existing one,
+for review or load testing. It creates the app in the source
+app's region and stack, copies its config vars (except those
+set by add-ons), provisions the same add-on plans, enables the
+same features, and releases the source app's current slug onto
+the new app with the same dyno sizes.
+
+Heroku Postgres databases are empty unless --fork-databases is
+given, in which case each one is created as a fork of the source
+app's database.
+
+Options:
+
+    -r <region>       Heroku region to create the app in
+                      (defaults to the source app's region)
+    -o <org>          name of Heroku organization to create app in
+                      (defaults to the profile's org, if any)
+    --fork-databases  fork the source app's Heroku Postgres databases
+
+Examples:
+
+    $ hk fork myapp myapp-loadtest
+    Created myapp-loadtest.
+    Added heroku-postgresql:crane to myapp-loadtest as heroku-postgresql-red.
+    Added papertrail:choklad to myapp-loadtest as papertrail-rural-1234.
+    Copied 12 config vars from myapp.
+    Enabled preboot.
+    Released myapp v42 to myapp-loadtest as v3.
+    Scaled web to 2X.
+
+    $ hk fork --fork-databases myapp myapp-review
+`,
+}
+
+func init() {
+	cmdFork.Flag.StringVarP(&flagRegion, "region", "r", "", "region name")
+	cmdFork.Flag.StringVarP(&flagOrgName, "org", "o", "", "organization name")
+	cmdFork.Flag.BoolVar(&flagForkDatabases, "fork-databases", false, "fork Heroku Postgres databases")
+}
+
+func runFork(cmd *Command, args []string) {
+	if len(args) != 2 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	source, appname := args[0], args[1]
+
+	src, err := fetchLiveApp(source)
+	must(err)
+	releases, err := client.ReleaseList(source, &heroku.ListRange{
+		Field:      "version",
+		Descending: true,
+		Limit:      1,
+	})
+	must(err)
+
+	// create the app, the same way as hk create
+	opts := heroku.OrganizationAppCreateOpts{
+		Name:   &appname,
+		Region: &src.App.Region.Name,
+		Stack:  &src.App.Stack.Name,
+	}
+	if flagRegion != "" {
+		opts.Region = &flagRegion
+	}
+	org := orgName()
+	if org == "personal" { // "personal" means "no org"
+		personal := true
+		opts.Personal = &personal
+	} else if org != "" {
+		opts.Organization = &org
+	}
+	app, err := client.OrganizationAppCreate(&opts)
+	must(err)
+	if app.Organization != nil {
+		log.Printf("Created %s in the %s org.", app.Name, app.Organization.Name)
+	} else {
+		log.Printf("Created %s.", app.Name)
+	}
+
+	addonVars := make(map[string]bool)
+	for _, a := range src.Addons {
+		for _, v := range a.ConfigVars {
+			addonVars[v] = true
+		}
+		var addonOpts heroku.AddonCreateOpts
+		if flagForkDatabases && addonService(a.Plan.Name) == hpgAddonName() {
+			config, err := forkDatabaseOpts(a, src.Config)
+			must(err)
+			addonOpts.Config = config
+		}
+		addon, err := client.AddonCreate(appname, a.Plan.Name, &addonOpts)
+		must(err)
+		log.Printf("Added %s to %s as %s.", addon.Plan.Name, appname, addon.Name)
+	}
+
+	config := make(map[string]*string)
+	for k, v := range src.Config {
+		if !addonVars[k] {
+			v := v
+			config[k] = &v
+		}
+	}
+	if len(config) > 0 {
+		_, err := client.ConfigVarUpdate(appname, config)
+		must(err)
+		log.Printf("Copied %d config vars from %s.", len(config), source)
+	}
+
+	features, err := client.AppFeatureList(appname, nil)
+	must(err)
+	enabled := make(map[string]bool)
+	for _, f := range features {
+		enabled[f.Name] = f.Enabled
+	}
+	for _, f := range src.Features {
+		if cur, ok := enabled[f.Name]; ok && cur == f.Enabled {
+			continue
+		}
+		_, err := client.AppFeatureUpdate(appname, f.Name, f.Enabled)
+		must(err)
+		if f.Enabled {
+			log.Printf("Enabled %s.", f.Name)
+		} else {
+			log.Printf("Disabled %s.", f.Name)
+		}
+	}
+
+	if len(releases) == 0 || releases[0].Slug == nil {
+		printWarning("%s has no slug to release, so %s hasn't been released.", source, appname)
+		return
+	}
+	desc := fmt.Sprintf("Fork of %s v%d", source, releases[0].Version)
+	rel, err := client.ReleaseCreate(appname, releases[0].Slug.Id, &heroku.ReleaseCreateOpts{Description: &desc})
+	must(err)
+	log.Printf("Released %s v%d to %s as v%d.", source, releases[0].Version, appname, rel.Version)
+
+	// process types exist once the slug is released, so sizes are set last
+	var updates []heroku.FormationBatchUpdateOpts
+	var sizes []string
+	for _, f := range src.Formation {
+		if f.Size == "" || f.Size == "1X" {
+			continue
+		}
+		size := f.Size
+		updates = append(updates, heroku.FormationBatchUpdateOpts{Process: f.Type, Size: &size})
+		sizes = append(sizes, f.Type+" to "+f.Size)
+	}
+	if len(updates) > 0 {
+		_, err := client.FormationBatchUpdate(appname, updates)
+		must(err)
+		sort.Strings(sizes)
+		log.Printf("Scaled %s.", strings.Join(sizes, ", "))
+	}
+}
+
+// forkDatabaseOpts returns the add-on config that creates a fork of a Heroku
+// Postgres add-on, resolving the database's name to its URL in the source
+// app's config.
+func forkDatabaseOpts(addon heroku.Addon, appEnv map[string]string) (*map[string]string, error) {
+	for _, v := range addon.ConfigVars {
+		name := pgEnvToDBName(v)
+		if dbNameToPgEnv(name) != v {
+			continue
+		}
+		config := map[string]string{"fork": name}
+		if err := hpgAddonOptResolve(&config, appEnv); err != nil {
+			return nil, err
+		}
+		return &config, nil
+	}
+	return nil, fmt.Errorf("could not find the database URL of %s", addon.Name)
+}
@@ -52,8 +52,8 @@ func httpGitURLPre() string {
 func gitDescribe(rels []*Release) error {
 	args := []string{"name-rev", "--tags", "--no-undefined", "--always", "--"}
 	for _, r := range rels {
-		if isDeploy(r.Description) {
-			r.Commit = r.Description[len(r.Description)-7:]
+		if sha := deploySHA(r.Description); sha != "" {
+			r.Commit = sha
 		}
 		if r.Commit != "" {
 			args = append(args, r.Commit)
@@ -79,6 +79,47 @@ func isDeploy(s string) bool {
 	return len(s) == len("Deploy 0000000") && strings.HasPrefix(s, "Deploy ")
 }
 
+// deploySHA returns the abbreviated commit SHA in the description of a
+// release created by a git push, or "" if it's another kind of release.
+func deploySHA(desc string) string {
+	if !isDeploy(desc) {
+		return ""
+	}
+	return desc[len(desc)-7:]
+}
+
+// gitLog returns the commits in the local repo that are reachable from to
+// but not from, newest first, as "<sha> <author>: <subject>" lines. from and
+// to come from the API, so they must be commit SHAs; anything else could be
+// taken by git for an option.
+func gitLog(from, to string) ([]string, error) {
+	if !isCommitSHA(from) || !isCommitSHA(to) {
+		return nil, fmt.Errorf("invalid commit range %q", from+".."+to)
+	}
+	out, err := exec.Command("git", "log", "--format=%h %an: %s", from+".."+to, "--").Output()
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimRight(string(out), "\n")
+	if s == "" {
+		return nil, nil
+	}
+	return strings.Split(s, "\n"), nil
+}
+
+// isCommitSHA reports whether s is a full or abbreviated commit SHA.
+func isCommitSHA(s string) bool {
+	if len(s) < 7 || len(s) > 40 {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
 func mapOutput(out []byte, sep, term string) map[string]string {
 	m := make(map[string]string)
 	lines := strings.Split(string(out), term)
@@ -141,11 +182,15 @@ func parseGitRemoteOutput(b []byte) (results map[string]string, err error) {
 }
 
 func gitConfigBool(name string) bool {
+	return gitConfig(name) == "true"
+}
+
+func gitConfig(name string) string {
 	b, err := exec.Command("git", "config", name).Output()
 	if err != nil {
-		return false
+		return ""
 	}
-	return strings.TrimSpace(string(b)) == "true"
+	return strings.TrimSpace(string(b))
 }
 
 func remoteFromGitConfig() string {
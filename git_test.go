@@ -61,3 +61,38 @@ func TestParseGitRemoteOutput(t *testing.T) {
 		}
 	}
 }
+
+func TestDeploySHA(t *testing.T) {
+	tests := []struct {
+		desc, want string
+	}{
+		{"Deploy 3ae20c2", "3ae20c2"},
+		{"Deploy 3ae20c2f", ""},
+		{"Rollback to v2", ""},
+	}
+	for _, tt := range tests {
+		if got := deploySHA(tt.desc); got != tt.want {
+			t.Errorf("deploySHA(%q) = %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestGitLogRejectsNonSHAs(t *testing.T) {
+	var tests = []struct {
+		from, to string
+	}{
+		{"--output=/tmp/x", "0123abc"},
+		{"0123abc", "-p"},
+		{"012345", "0123abc"},
+		{"0123abg", "0123abc"},
+		{"0123abc", "0123456789abcdef0123456789abcdef012345678"},
+	}
+	for _, tt := range tests {
+		if _, err := gitLog(tt.from, tt.to); err == nil {
+			t.Errorf("gitLog(%q, %q): expected an error", tt.from, tt.to)
+		}
+	}
+	if !isCommitSHA("0123abc") || !isCommitSHA("0123456789ABCDEF0123456789abcdef01234567") {
+		t.Errorf("isCommitSHA rejected valid SHAs")
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	flagGuardInterval    time.Duration
+	flagGuardBackoff     time.Duration
+	flagGuardMaxRestarts int
+)
+
+var cmdGuard = &Command{
+	Run:      runGuard,
+	Usage:    "guard [--apps <app>,...] [--interval <duration>] [--backoff <duration>] [--max-restarts <n>] [<type or name>...]",
+	NeedsApp: true,
+	Category: "dyno",
+	Short:    "restart dynos as they crash" + extra,
+	Long: `
+Guard watches apps' dynos and restarts the ones that crash,
+logging everything it does, until it's interrupted. It then
+prints a summary of the restarts.
+
+Each dyno is restarted again only after a backoff, which starts
+at --backoff and doubles with every restart. After --max-restarts
+restarts, guard gives up on the dyno. A dyno that stays up for
+10 minutes has its backoff and count reset.
+
+Options:
+
+    --apps <app>,...          watch these apps, instead of the
+                              current app
+    --interval <duration>     how often to check dynos (default 30s)
+    --backoff <duration>      wait before a dyno's second restart
+                              (default 1m)
+    --max-restarts <n>        restarts of a dyno before giving up
+                              (default 5)
+
+Examples:
+
+    $ hk guard --apps myapp,myapp-worker worker
+    Guarding worker dynos on myapp, myapp-worker.
+    14:02:31 myapp-worker worker.2 crashed; restarted (1 of 5).
+    14:03:01 myapp-worker worker.2 crashed; restarting in 30s.
+    14:03:31 myapp-worker worker.2 crashed; restarted (2 of 5).
+    ^C
+    Guarded myapp, myapp-worker for 1m12s.
+    Restarts: myapp-worker/worker.2 (2)
+`,
+}
+
+func init() {
+	cmdGuard.Flag.StringVar(&flagApps, "apps", "", "comma-separated app names")
+	cmdGuard.Flag.DurationVar(&flagGuardInterval, "interval", 30*time.Second, "how often to check dynos")
+	cmdGuard.Flag.DurationVar(&flagGuardBackoff, "backoff", time.Minute, "initial wait between restarts of a dyno")
+	cmdGuard.Flag.IntVar(&flagGuardMaxRestarts, "max-restarts", 5, "restarts of a dyno before giving up")
+}
+
+// guardResetAfter is how long a dyno must stay up for guard to forget its
+// restarts.
+const guardResetAfter = 10 * time.Minute
+
+func runGuard(cmd *Command, names []string) {
+	apps := appsFlag()
+	if len(apps) == 0 {
+		apps = []string{mustApp()}
+	}
+	if flagGuardInterval <= 0 || flagGuardBackoff < 0 || flagGuardMaxRestarts < 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+
+	what := "dynos"
+	if len(names) > 0 {
+		what = strings.Join(names, ", ") + " dynos"
+	}
+	fmt.Printf("Guarding %s on %s.\n", what, strings.Join(apps, ", "))
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+
+	start := time.Now()
+	g := newDynoGuard(flagGuardBackoff, flagGuardMaxRestarts)
+	for {
+		for _, appname := range apps {
+			guardApp(g, appname, names)
+		}
+		select {
+		case <-sigc:
+			fmt.Println()
+			fmt.Printf("Guarded %s for %s.\n", strings.Join(apps, ", "), strings.TrimSpace(prettyDuration{time.Since(start)}.String()))
+			g.printSummary(os.Stdout)
+			return
+		case <-time.After(flagGuardInterval):
+		}
+	}
+}
+
+// guardApp checks an app's dynos once, restarting crashed ones that are
+// due.
+func guardApp(g *dynoGuard, appname string, names []string) {
+	dynos, err := client.DynoList(appname, nil)
+	if err != nil {
+		guardLog(appname, "couldn't list dynos: %s", err)
+		return
+	}
+	now := time.Now()
+	for _, d := range filterDynos(dynos, names) {
+		if d.Type == "run" {
+			continue
+		}
+		key := appname + "/" + d.Name
+		switch a := g.check(key, d.State, now); a.action {
+		case guardRestart:
+			if err := client.DynoRestart(appname, d.Name); err != nil {
+				guardLog(appname, "%s crashed; couldn't restart it: %s", d.Name, err)
+				continue
+			}
+			n := g.restarted(key, now)
+			guardLog(appname, "%s crashed; restarted (%d of %d).", d.Name, n, g.max)
+		case guardWait:
+			guardLog(appname, "%s crashed; restarting in %s.", d.Name, a.wait)
+		case guardGiveUp:
+			guardLog(appname, "%s crashed; gave up after %d restarts.", d.Name, g.max)
+		}
+	}
+}
+
+func guardLog(appname, format string, a ...interface{}) {
+	fmt.Printf("%s %s %s\n", time.Now().Format("15:04:05"), appname, fmt.Sprintf(format, a...))
+}
+
+const (
+	guardNone = iota
+	guardRestart
+	guardWait
+	guardGiveUp
+)
+
+type guardAction struct {
+	action int
+	wait   time.Duration // for guardWait
+}
+
+// A dynoGuard decides when crashed dynos should be restarted, backing off
+// exponentially for each dyno, and giving up after max restarts.
+type dynoGuard struct {
+	backoff time.Duration
+	max     int
+	dynos   map[string]*guardedDyno
+
+	// for the summary, which outlasts resets
+	restarts map[string]int
+	gaveUp   []string
+}
+
+type guardedDyno struct {
+	restarts int
+	next     time.Time // earliest time of the next restart
+	upSince  time.Time
+	waiting  bool // the wait for the next restart has been reported
+	gaveUp   bool
+}
+
+func newDynoGuard(backoff time.Duration, max int) *dynoGuard {
+	return &dynoGuard{
+		backoff:  backoff,
+		max:      max,
+		dynos:    make(map[string]*guardedDyno),
+		restarts: make(map[string]int),
+	}
+}
+
+// check returns what to do about a dyno in the given state. Waiting for a
+// restart and giving up are only reported once.
+func (g *dynoGuard) check(key, state string, now time.Time) guardAction {
+	gd := g.dynos[key]
+	if gd == nil {
+		gd = new(guardedDyno)
+		g.dynos[key] = gd
+	}
+	if state != "crashed" {
+		if state == "up" {
+			if gd.upSince.IsZero() {
+				gd.upSince = now
+			}
+			if now.Sub(gd.upSince) >= guardResetAfter {
+				*gd = guardedDyno{upSince: gd.upSince}
+			}
+		}
+		return guardAction{action: guardNone}
+	}
+	gd.upSince = time.Time{}
+
+	switch {
+	case gd.gaveUp:
+		return guardAction{action: guardNone}
+	case gd.waiting && now.Before(gd.next):
+		return guardAction{action: guardNone}
+	case gd.restarts >= g.max:
+		gd.gaveUp = true
+		g.gaveUp = append(g.gaveUp, key)
+		return guardAction{action: guardGiveUp}
+	case now.Before(gd.next):
+		gd.waiting = true
+		return guardAction{action: guardWait, wait: gd.next.Sub(now)}
+	}
+	return guardAction{action: guardRestart}
+}
+
+// restarted records a restart of a dyno, returning how many times it has
+// been restarted.
+func (g *dynoGuard) restarted(key string, now time.Time) int {
+	gd := g.dynos[key]
+	gd.next = now.Add(g.backoff << uint(gd.restarts))
+	gd.restarts++
+	gd.waiting = false
+	g.restarts[key]++
+	return gd.restarts
+}
+
+// printSummary prints the dynos that were restarted, and those given up on.
+func (g *dynoGuard) printSummary(w io.Writer) {
+	if len(g.restarts) == 0 {
+		fmt.Fprintln(w, "No dynos were restarted.")
+		return
+	}
+	var keys []string
+	for key := range g.restarts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var s []string
+	for _, key := range keys {
+		s = append(s, fmt.Sprintf("%s (%d)", key, g.restarts[key]))
+	}
+	fmt.Fprintf(w, "Restarts: %s\n", strings.Join(s, ", "))
+	if len(g.gaveUp) > 0 {
+		gaveUp := append([]string(nil), g.gaveUp...)
+		sort.Strings(gaveUp)
+		fmt.Fprintf(w, "Gave up on: %s\n", strings.Join(gaveUp, ", "))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDynoGuard(t *testing.T) {
+	t0 := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	g := newDynoGuard(time.Minute, 2)
+	const key = "myapp/worker.1"
+
+	steps := []struct {
+		after  time.Duration
+		state  string
+		action int
+	}{
+		{0, "crashed", guardRestart},
+		{30 * time.Second, "crashed", guardWait},
+		{45 * time.Second, "crashed", guardNone}, // wait already reported
+		{time.Minute, "crashed", guardRestart},
+		{90 * time.Second, "up", guardNone},
+		{2 * time.Minute, "crashed", guardGiveUp},
+		{10 * time.Minute, "crashed", guardNone},
+	}
+	for i, s := range steps {
+		now := t0.Add(s.after)
+		a := g.check(key, s.state, now)
+		if a.action != s.action {
+			t.Fatalf("step %d: check(%s) = %d, want %d", i, s.state, a.action, s.action)
+		}
+		if a.action == guardRestart {
+			g.restarted(key, now)
+		}
+	}
+	if g.dynos[key].restarts != 2 {
+		t.Errorf("restarts = %d, want 2", g.dynos[key].restarts)
+	}
+
+	// the backoff doubles with each restart
+	g = newDynoGuard(time.Minute, 5)
+	g.check(key, "crashed", t0)
+	g.restarted(key, t0)
+	g.check(key, "crashed", t0.Add(time.Minute))
+	g.restarted(key, t0.Add(time.Minute))
+	if a := g.check(key, "crashed", t0.Add(2*time.Minute)); a.action != guardWait || a.wait != time.Minute {
+		t.Errorf("after 2 restarts, check = %+v, want a wait of 1m", a)
+	}
+
+	// staying up resets the count
+	g.check(key, "up", t0.Add(3*time.Minute))
+	g.check(key, "up", t0.Add(3*time.Minute+guardResetAfter))
+	if n := g.dynos[key].restarts; n != 0 {
+		t.Errorf("after staying up, restarts = %d, want 0", n)
+	}
+}
+
+func TestDynoGuardSummary(t *testing.T) {
+	t0 := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	g := newDynoGuard(0, 1)
+
+	var buf bytes.Buffer
+	g.printSummary(&buf)
+	if got := buf.String(); got != "No dynos were restarted.\n" {
+		t.Errorf("with no restarts, summary = %q", got)
+	}
+
+	for _, key := range []string{"myapp/worker.2", "myapp/web.1"} {
+		g.check(key, "crashed", t0)
+		g.restarted(key, t0)
+	}
+	g.check("myapp/worker.2", "crashed", t0.Add(time.Minute))
+	buf.Reset()
+	g.printSummary(&buf)
+	want := "Restarts: myapp/web.1 (1), myapp/worker.2 (1)\nGave up on: myapp/worker.2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
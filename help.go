@@ -38,6 +38,51 @@ HKHEADER
   A NL-separated list of fields to set in each API request header.
   These override any fields set by hk if they have the same name.
 
+HKSLUGURL
+
+  When set, hk deploy-slug uploads slugs to this URL instead of the
+  one returned by the API. This is mainly useful for testing.
+
+HK_NONINTERACTIVE
+
+  When set, hk never prompts for input. Commands that would prompt
+  for confirmation, login details, or a passphrase exit immediately
+  with status 84 instead. Destructive commands can be confirmed
+  with --confirm <app>.
+
+HKERRORS
+
+  When set to json, hk prints fatal errors to stderr as JSON
+  objects with the API's error id, message, and URL, and the exit
+  code. See doc/exit-codes.md for the list of exit codes.
+
+HKRETRIES
+
+  The number of times hk retries an API request that failed for a
+  temporary reason, such as a server error or rate limiting.
+  Defaults to 4. Set it to 0 to disable retries.
+
+HKCREDSTORE
+
+  Where credentials are stored: netrc (the default), encrypted, or
+  secret-service. Overrides the hk.credential-store git config
+  setting. See 'hk help creds-migrate'.
+
+HKPASSPHRASE
+
+  The passphrase for the encrypted credential store. If unset, hk
+  prompts for it whenever credentials are needed.
+
+HKCREDENTIALS_PATH
+
+  The location of the encrypted credential store. Defaults to
+  ~/.hk/credentials.
+
+HKPROFILE
+
+  The name of the account profile to use, unless another is given
+  with -P. See 'hk help profiles'.
+
 HKPATH
 
   A list of directories to search for plugins. This variable takes
@@ -132,7 +177,7 @@ func runHelp(cmd *Command, args []string) {
 	}
 
 	log.Printf("Unknown help topic: %q. Run 'hk help'.\n", args[0])
-	os.Exit(2)
+	exit(2)
 }
 
 func maxStrLen(strs []string) (strlen int) {
@@ -145,7 +190,7 @@ func maxStrLen(strs []string) (strlen int) {
 }
 
 var usageTemplate = template.Must(template.New("usage").Parse(`
-Usage: hk <command> [-a <app or remote>] [options] [arguments]
+Usage: hk [-P <profile>] <command> [-a <app or remote>] [options] [arguments]
 
 
 Commands:
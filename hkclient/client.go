@@ -2,29 +2,45 @@ package hkclient
 
 import (
 	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 	"github.com/heroku/hk/postgresql"
 )
 
 type Clients struct {
-	ApiURL string
-	Client *heroku.Client
+	ApiURL  string
+	Client  *heroku.Client
+	Profile *Profile
 
 	PgClient *postgresql.Client
 }
 
-func New(nrc *NetRc, agent string) (*Clients, error) {
+// New returns API clients using the settings of profile p, or of the default
+// profile if p is nil, and its credentials from store. Environment variables
+// such as HEROKU_API_URL override the profile's settings.
+//
+// The credentials are looked up when the first request is made, so that
+// commands which make none don't have to unlock the store.
+func New(store CredStore, agent string, p *Profile) (*Clients, error) {
 	userAgent := agent + " " + heroku.DefaultUserAgent
-	ste := Clients{}
+	if p == nil {
+		p = &Profile{Name: DefaultProfile}
+	}
+	ste := Clients{Profile: p}
 
 	disableSSLVerify := false
 	ste.ApiURL = heroku.DefaultAPIURL
+	if p.APIURL != "" {
+		ste.ApiURL = p.APIURL
+	}
 	if s := os.Getenv("HEROKU_API_URL"); s != "" {
 		ste.ApiURL = s
 		disableSSLVerify = true
@@ -35,40 +51,50 @@ func New(nrc *NetRc, agent string) (*Clients, error) {
 		return nil, err
 	}
 
-	user, pass, err := nrc.GetCreds(apiURL)
-	if err != nil {
-		return nil, err
-	}
-
 	debug := os.Getenv("HKDEBUG") != ""
 	ste.Client = &heroku.Client{
 		URL:       ste.ApiURL,
-		Username:  user,
-		Password:  pass,
 		UserAgent: userAgent,
 		Debug:     debug,
 	}
 	ste.PgClient = &postgresql.Client{
-		Username:  user,
-		Password:  pass,
 		UserAgent: userAgent,
 		Debug:     debug,
 	}
 
 	tr := &http.Transport{}
-	ste.Client.HTTP = &http.Client{Transport: tr}
-	ste.PgClient.HTTP = &http.Client{Transport: tr}
+	rt := NewRetryTransport(tr)
+	rt.Debug = debug
+	if s := os.Getenv("HKRETRIES"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid HKRETRIES %q", s)
+		}
+		rt.MaxRetries = n
+	}
+	ct := &CredsTransport{
+		Transport: rt,
+		Creds: func() (string, string, error) {
+			return ProfileCreds(store, apiURL, p)
+		},
+	}
+	ste.Client.HTTP = &http.Client{Transport: ct}
+	ste.PgClient.HTTP = &http.Client{Transport: ct}
 
 	if disableSSLVerify || os.Getenv("HEROKU_SSL_VERIFY") == "disable" {
 		tr.TLSClientConfig = &tls.Config{
 			InsecureSkipVerify: true,
 		}
 	}
+	pgHost := p.PostgresqlHost
 	if s := os.Getenv("HEROKU_POSTGRESQL_HOST"); s != "" {
-		ste.PgClient.StarterURL = "https://" + s +
+		pgHost = s
+	}
+	if pgHost != "" {
+		ste.PgClient.StarterURL = "https://" + pgHost +
 			".herokuapp.com" + postgresql.DefaultAPIPath
 
-		ste.PgClient.URL = "https://" + s + ".herokuapp.com" +
+		ste.PgClient.URL = "https://" + pgHost + ".herokuapp.com" +
 			postgresql.DefaultAPIPath
 	}
 	if s := os.Getenv("SHOGUN"); s != "" {
@@ -97,7 +123,13 @@ func New(nrc *NetRc, agent string) (*Clients, error) {
 			herokuAgentSocket = homePath() + herokuAgentSocket[1:]
 		}
 
-		tr.Dial = func(_ string, _ string) (net.Conn, error) {
+		// the clients downgrade their requests to plain HTTP for heroku-agent,
+		// which secures them itself. Other requests made with the clients'
+		// transport, such as slug uploads, are dialed directly.
+		tr.Dial = func(network, addr string) (net.Conn, error) {
+			if _, port, _ := net.SplitHostPort(addr); port != "80" {
+				return net.Dial(network, addr)
+			}
 			return net.Dial("unix", herokuAgentSocket)
 		}
 
@@ -107,3 +139,34 @@ func New(nrc *NetRc, agent string) (*Clients, error) {
 
 	return &ste, nil
 }
+
+// A CredsTransport adds a password to requests made without one, looking it
+// up the first time it's needed.
+type CredsTransport struct {
+	// Transport performs the underlying requests.
+	Transport http.RoundTripper
+
+	// Creds returns the credentials to use. It's called at most once.
+	Creds func() (user, pass string, err error)
+
+	once sync.Once
+	pass string
+	err  error
+}
+
+func (t *CredsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.once.Do(func() {
+		_, t.pass, t.err = t.Creds()
+	})
+	if t.err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, t.err
+	}
+	if _, pass, ok := req.BasicAuth(); ok && pass == "" && t.pass != "" {
+		req = req.Clone(req.Context())
+		req.SetBasicAuth("", t.pass)
+	}
+	return t.Transport.RoundTrip(req)
+}
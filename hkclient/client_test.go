@@ -0,0 +1,62 @@
+package hkclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCredsTransport(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pass, _ := r.BasicAuth()
+		got = append(got, pass)
+	}))
+	defer ts.Close()
+
+	lookups := 0
+	ct := &CredsTransport{
+		Transport: http.DefaultTransport,
+		Creds: func() (string, string, error) {
+			lookups++
+			return "user", "secret", nil
+		},
+	}
+	if lookups != 0 {
+		t.Fatalf("creds looked up before any request")
+	}
+	c := &http.Client{Transport: ct}
+	for _, pass := range []string{"", "explicit", ""} {
+		req, _ := http.NewRequest("GET", ts.URL, nil)
+		req.SetBasicAuth("", pass)
+		res, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if lookups != 1 {
+		t.Errorf("creds looked up %d times, want 1", lookups)
+	}
+	want := []string{"secret", "explicit", "secret"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("passwords = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCredsTransportError(t *testing.T) {
+	ct := &CredsTransport{
+		Transport: http.DefaultTransport,
+		Creds: func() (string, string, error) {
+			return "", "", ErrBadPassphrase
+		},
+	}
+	c := &http.Client{Transport: ct}
+	_, err := c.Get("http://127.0.0.1:1/")
+	if !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("err = %v, want %v", err, ErrBadPassphrase)
+	}
+}
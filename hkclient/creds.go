@@ -1,7 +1,6 @@
 package hkclient
 
 import (
-	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
@@ -35,18 +34,17 @@ func LoadNetRc() (nrc *NetRc, err error) {
 }
 
 func (nrc *NetRc) GetCreds(apiURL *url.URL) (user, pass string, err error) {
-	if err != nil {
-		return "", "", fmt.Errorf("invalid API URL: %s", err)
-	}
-	if apiURL.Host == "" {
-		return "", "", fmt.Errorf("missing API host: %s", apiURL)
-	}
-	if apiURL.User != nil {
-		pw, _ := apiURL.User.Password()
-		return apiURL.User.Username(), pw, nil
-	}
+	return nrc.GetProfileCreds(apiURL, nil)
+}
+
+// GetProfileCreds returns the credentials stored for profile p. Credentials
+// included in apiURL take precedence.
+func (nrc *NetRc) GetProfileCreds(apiURL *url.URL, p *Profile) (user, pass string, err error) {
+	return ProfileCreds(nrc, apiURL, p)
+}
 
-	m := nrc.FindMachine(apiURL.Host)
+func (nrc *NetRc) Creds(machine string) (user, pass string, err error) {
+	m := nrc.FindMachine(machine)
 	if m == nil {
 		return "", "", nil
 	}
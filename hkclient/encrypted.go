@@ -0,0 +1,172 @@
+package hkclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for deriving the encryption key from the passphrase
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var ErrBadPassphrase = errors.New("incorrect passphrase or corrupt credentials file")
+
+func encryptedFilePath() string {
+	if s := os.Getenv("HKCREDENTIALS_PATH"); s != "" {
+		return s
+	}
+	return filepath.Join(HomePath(), ".hk", "credentials")
+}
+
+// An EncryptedFile stores credentials in a file encrypted at rest with
+// AES-GCM, using a key derived from a passphrase with scrypt. The file is
+// decrypted the first time credentials are needed.
+type EncryptedFile struct {
+	Path string
+
+	// Passphrase is called at most once, to get the passphrase protecting
+	// the file.
+	Passphrase func() (string, error)
+
+	key   []byte
+	salt  []byte
+	creds map[string]storedCred
+}
+
+type storedCred struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// encryptedFileData is the on-disk format of an EncryptedFile.
+type encryptedFileData struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func (f *EncryptedFile) Creds(machine string) (user, pass string, err error) {
+	if err := f.load(); err != nil {
+		return "", "", err
+	}
+	c := f.creds[machine]
+	return c.Login, c.Password, nil
+}
+
+func (f *EncryptedFile) SaveCreds(machine, user, pass string) error {
+	if err := f.load(); err != nil {
+		return err
+	}
+	f.creds[machine] = storedCred{Login: user, Password: pass}
+	return f.save()
+}
+
+func (f *EncryptedFile) RemoveCreds(machine string) error {
+	if err := f.load(); err != nil {
+		return err
+	}
+	delete(f.creds, machine)
+	return f.save()
+}
+
+func (f *EncryptedFile) load() error {
+	if f.creds != nil {
+		return nil
+	}
+	b, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		// the file is created, with a new salt, on the first save
+		f.creds = make(map[string]storedCred)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var data encryptedFileData
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	f.salt = data.Salt
+	gcm, err := f.cipher()
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, data.Nonce, data.Data, nil)
+	if err != nil {
+		return ErrBadPassphrase
+	}
+	creds := make(map[string]storedCred)
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return err
+	}
+	f.creds = creds
+	return nil
+}
+
+func (f *EncryptedFile) save() error {
+	if f.salt == nil {
+		f.salt = make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, f.salt); err != nil {
+			return err
+		}
+	}
+	gcm, err := f.cipher()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(f.creds)
+	if err != nil {
+		return err
+	}
+	data := encryptedFileData{Salt: f.salt, Nonce: make([]byte, gcm.NonceSize())}
+	if _, err := io.ReadFull(rand.Reader, data.Nonce); err != nil {
+		return err
+	}
+	data.Data = gcm.Seal(nil, data.Nonce, plain, nil)
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.Path, b, 0600)
+}
+
+// cipher derives the key from the passphrase and f.salt, if it hasn't been
+// derived already, and returns an AES-GCM cipher using it.
+func (f *EncryptedFile) cipher() (cipher.AEAD, error) {
+	if f.key == nil {
+		if f.Passphrase == nil {
+			return nil, errors.New("no passphrase for encrypted credentials")
+		}
+		passphrase, err := f.Passphrase()
+		if err != nil {
+			return nil, err
+		}
+		key, err := scrypt.Key([]byte(passphrase), f.salt, scryptN, scryptR, scryptP, scryptKeyLen)
+		if err != nil {
+			return nil, err
+		}
+		f.key = key
+	}
+	block, err := aes.NewCipher(f.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
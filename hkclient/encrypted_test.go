@@ -0,0 +1,52 @@
+package hkclient
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hkclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials")
+
+	passphrase := func(s string) func() (string, error) {
+		return func() (string, error) { return s, nil }
+	}
+
+	f := &EncryptedFile{Path: path, Passphrase: passphrase("sekrit")}
+	if err := f.SaveCreds("api.heroku.com", "user@test.com", "faketestpassword"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "faketestpassword") {
+		t.Errorf("expected password to be encrypted at rest")
+	}
+
+	f = &EncryptedFile{Path: path, Passphrase: passphrase("sekrit")}
+	user, pass, err := f.Creds("api.heroku.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "user@test.com" || pass != "faketestpassword" {
+		t.Errorf("expected user@test.com:faketestpassword, got %s:%s", user, pass)
+	}
+	if user, pass, _ = f.Creds("api.heroku.com/work"); user != "" || pass != "" {
+		t.Errorf("expected no creds for unknown machine, got %s:%s", user, pass)
+	}
+
+	f = &EncryptedFile{Path: path, Passphrase: passphrase("wrong")}
+	if _, _, err := f.Creds("api.heroku.com"); err != ErrBadPassphrase {
+		t.Errorf("expected ErrBadPassphrase, got %v", err)
+	}
+}
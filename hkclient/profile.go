@@ -0,0 +1,111 @@
+package hkclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DefaultProfile is the name of the profile used when none is selected. Its
+// credentials are stored under the bare API hostname, as they were before
+// profiles existed.
+const DefaultProfile = "default"
+
+var ErrProfileNotFound = errors.New("profile not found")
+
+// A Profile is a named set of account settings. Each profile has its own
+// credentials, and may override the API URL, default organization, and
+// Heroku Postgres API host.
+type Profile struct {
+	Name           string `json:"name"`
+	APIURL         string `json:"api_url,omitempty"`
+	Org            string `json:"org,omitempty"`
+	PostgresqlHost string `json:"postgresql_host,omitempty"`
+}
+
+// IsDefault returns true if p is the default profile.
+func (p *Profile) IsDefault() bool {
+	return p == nil || p.Name == "" || p.Name == DefaultProfile
+}
+
+// Machine returns the name of the netrc machine holding p's credentials for
+// the given API host.
+func (p *Profile) Machine(host string) string {
+	if p.IsDefault() {
+		return host
+	}
+	return host + "/" + p.Name
+}
+
+func profilesPath() string {
+	return filepath.Join(HomePath(), ".hk", "profiles.json")
+}
+
+// LoadProfiles returns all saved profiles.
+func LoadProfiles() ([]*Profile, error) {
+	b, err := ioutil.ReadFile(profilesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var profiles []*Profile
+	if err := json.Unmarshal(b, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// LoadProfile returns the named profile, or the default profile if name is
+// empty. The default profile always exists, even if it was never saved. For
+// any other unsaved profile, LoadProfile returns an empty profile with the
+// given name along with ErrProfileNotFound.
+func LoadProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	if name == DefaultProfile {
+		return &Profile{Name: name}, nil
+	}
+	return &Profile{Name: name}, ErrProfileNotFound
+}
+
+// SaveProfile adds p to the saved profiles, replacing any existing profile
+// with the same name.
+func SaveProfile(p *Profile) error {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == p.Name {
+			profiles[i] = p
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, p)
+	}
+
+	b, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(profilesPath()), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(profilesPath(), append(b, '\n'), 0600)
+}
@@ -0,0 +1,72 @@
+package hkclient
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestProfileMachine(t *testing.T) {
+	var nilp *Profile
+	tests := []struct {
+		p    *Profile
+		want string
+	}{
+		{nilp, "api.heroku.com"},
+		{&Profile{}, "api.heroku.com"},
+		{&Profile{Name: DefaultProfile}, "api.heroku.com"},
+		{&Profile{Name: "work"}, "api.heroku.com/work"},
+	}
+	for _, test := range tests {
+		if got := test.p.Machine("api.heroku.com"); got != test.want {
+			t.Errorf("expected machine %q for %v, got %q", test.want, test.p, got)
+		}
+	}
+}
+
+func TestSaveAndLoadProfile(t *testing.T) {
+	home, err := ioutil.TempDir("", "hkclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", oldHome)
+
+	p, err := LoadProfile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != DefaultProfile {
+		t.Errorf("expected default profile, got %q", p.Name)
+	}
+
+	p, err = LoadProfile("work")
+	if err != ErrProfileNotFound {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+	if p == nil || p.Name != "work" {
+		t.Fatalf("expected empty profile named work, got %v", p)
+	}
+
+	p.Org = "acme"
+	if err := SaveProfile(p); err != nil {
+		t.Fatal(err)
+	}
+	p.APIURL = "https://api.example.com"
+	if err := SaveProfile(p); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 {
+		t.Fatalf("expected 1 profile, got %d", len(profiles))
+	}
+	if *profiles[0] != *p {
+		t.Errorf("expected %v, got %v", *p, *profiles[0])
+	}
+}
@@ -0,0 +1,221 @@
+package hkclient
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 4
+
+	// rateLimitLowWater is the number of remaining API requests below which
+	// RetryTransport starts spacing out requests.
+	rateLimitLowWater = 10
+
+	// rateLimitRefill is how long the Heroku API takes to replenish a single
+	// request token (4500 per hour).
+	rateLimitRefill = time.Hour / 4500
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// A RetryTransport retries requests that fail for reasons which are likely to
+// be temporary. Requests that were rate limited (429) are always retried,
+// after the delay given in the Retry-After header if there is one. Idempotent
+// requests are also retried after server errors (5xx) and network errors such
+// as connection resets. Retries are spaced out with jittered exponential
+// backoff.
+//
+// RetryTransport also watches the RateLimit-Remaining header returned by the
+// Heroku API, and slows down when few requests remain so that long-running
+// scripts don't hit the limit at all.
+type RetryTransport struct {
+	// Transport performs the underlying requests. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// MaxRetries is the number of times a request may be retried.
+	MaxRetries int
+
+	// Debug logs each retry to stderr.
+	Debug bool
+
+	// sleep is time.Sleep, overridden in tests.
+	sleep func(time.Duration)
+
+	mu        sync.Mutex
+	remaining int // from the last RateLimit-Remaining header, or -1
+}
+
+func NewRetryTransport(tr http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Transport:  tr,
+		MaxRetries: DefaultMaxRetries,
+		remaining:  -1,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr := t.Transport
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	t.throttle()
+
+	for attempt := 0; ; attempt++ {
+		res, err := tr.RoundTrip(req)
+		if res != nil {
+			t.recordRateLimit(res)
+		}
+		if attempt >= t.MaxRetries || !shouldRetry(req, res, err) {
+			return res, err
+		}
+		next, berr := rewindBody(req)
+		if berr != nil {
+			return res, err
+		}
+
+		delay := backoff(attempt)
+		if res != nil {
+			if d, ok := retryAfter(res); ok {
+				delay = d
+			}
+			// drain and close the body so the connection can be reused
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		if t.Debug {
+			reason := ""
+			if err != nil {
+				reason = err.Error()
+			} else {
+				reason = res.Status
+			}
+			log.Printf("retrying %s %s in %s: %s", req.Method, req.URL.Path, delay, reason)
+		}
+		t.doSleep(delay)
+		req = next
+	}
+}
+
+// throttle delays a request if the rate limit is nearly exhausted, spacing
+// requests out at about the rate the API replenishes them.
+func (t *RetryTransport) throttle() {
+	t.mu.Lock()
+	remaining := t.remaining
+	t.mu.Unlock()
+	if remaining >= 0 && remaining < rateLimitLowWater {
+		t.doSleep(time.Duration(rateLimitLowWater-remaining) * rateLimitRefill)
+	}
+}
+
+func (t *RetryTransport) recordRateLimit(res *http.Response) {
+	s := res.Header.Get("RateLimit-Remaining")
+	if s == "" {
+		return
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		t.mu.Lock()
+		t.remaining = n
+		t.mu.Unlock()
+	}
+}
+
+func (t *RetryTransport) doSleep(d time.Duration) {
+	if t.sleep != nil {
+		t.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(req.Method) && isTemporaryNetError(err)
+	}
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case res.StatusCode/100 == 5:
+		return isIdempotent(req.Method)
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+func isTemporaryNetError(err error) bool {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// rewindBody returns a copy of req whose body can be sent again.
+func rewindBody(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errNoRewind
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next := *req
+	next.Body = body
+	return &next, nil
+}
+
+var errNoRewind = errors.New("request body cannot be rewound")
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	s := res.Header.Get("Retry-After")
+	if s == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(s); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(s); err == nil {
+		d = t.Sub(time.Now())
+	} else {
+		return 0, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	if d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d, true
+}
+
+// backoff returns a random delay of up to retryBaseDelay*2^attempt, capped at
+// retryMaxDelay.
+func backoff(attempt int) time.Duration {
+	max := retryBaseDelay << uint(attempt)
+	if max <= 0 || max > retryMaxDelay {
+		max = retryMaxDelay
+	}
+	return max/2 + time.Duration(rand.Int63n(int64(max/2)))
+}
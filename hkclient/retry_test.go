@@ -0,0 +1,106 @@
+package hkclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRetryTransport() (*RetryTransport, *[]time.Duration) {
+	var slept []time.Duration
+	rt := NewRetryTransport(http.DefaultTransport)
+	rt.sleep = func(d time.Duration) { slept = append(slept, d) }
+	return rt, &slept
+}
+
+func TestRetryTransport(t *testing.T) {
+	var tests = []struct {
+		method   string
+		statuses []int
+		want     int
+		requests int
+	}{
+		{"GET", []int{200}, 200, 1},
+		{"GET", []int{503, 502, 200}, 200, 3},
+		{"DELETE", []int{500, 200}, 200, 2},
+		{"POST", []int{500, 200}, 500, 1},
+		{"POST", []int{429, 201}, 201, 2},
+		{"GET", []int{404, 200}, 404, 1},
+		{"GET", []int{500, 500, 500, 500, 500, 200}, 500, 5},
+	}
+	for _, tt := range tests {
+		n := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "POST" {
+				buf := make([]byte, 10)
+				m, _ := r.Body.Read(buf)
+				if string(buf[:m]) != "body" {
+					t.Errorf("%s attempt %d: body = %q", r.Method, n, buf[:m])
+				}
+			}
+			w.WriteHeader(tt.statuses[n])
+			n++
+		}))
+		rt, _ := newTestRetryTransport()
+		c := &http.Client{Transport: rt}
+		req, _ := http.NewRequest(tt.method, ts.URL, strings.NewReader("body"))
+		res, err := c.Do(req)
+		ts.Close()
+		if err != nil {
+			t.Errorf("%s %v: %s", tt.method, tt.statuses, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode != tt.want {
+			t.Errorf("%s %v: status = %d, want %d", tt.method, tt.statuses, res.StatusCode, tt.want)
+		}
+		if n != tt.requests {
+			t.Errorf("%s %v: %d requests, want %d", tt.method, tt.statuses, n, tt.requests)
+		}
+	}
+}
+
+func TestRetryTransportRetryAfter(t *testing.T) {
+	n := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n == 0 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(429)
+		}
+		n++
+	}))
+	defer ts.Close()
+
+	rt, slept := newTestRetryTransport()
+	res, err := (&http.Client{Transport: rt}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if len(*slept) != 1 || (*slept)[0] != 7*time.Second {
+		t.Errorf("slept %v, want [7s]", *slept)
+	}
+}
+
+func TestRetryTransportRateLimitRemaining(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Remaining", "8")
+	}))
+	defer ts.Close()
+
+	rt, slept := newTestRetryTransport()
+	c := &http.Client{Transport: rt}
+	for i := 0; i < 2; i++ {
+		res, err := c.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	want := 2 * rateLimitRefill
+	if len(*slept) != 1 || (*slept)[0] != want {
+		t.Errorf("slept %v, want [%s]", *slept, want)
+	}
+}
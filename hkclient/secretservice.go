@@ -0,0 +1,61 @@
+package hkclient
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SecretService stores credentials with the freedesktop.org Secret Service
+// (e.g. GNOME Keyring or KWallet) over D-Bus, using libsecret's secret-tool
+// command. Each machine's login and password are saved together as a single
+// secret.
+type SecretService struct{}
+
+func (SecretService) Creds(machine string) (user, pass string, err error) {
+	out, err := secretTool(nil, "lookup", "service", "hk", "machine", machine)
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) == 0 {
+			// secret-tool exits with status 1 and no message if there's no secret
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimRight(string(out), "\n"), "\n", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("malformed secret for %s", machine)
+	}
+	return parts[0], parts[1], nil
+}
+
+func (SecretService) SaveCreds(machine, user, pass string) error {
+	_, err := secretTool(strings.NewReader(user+"\n"+pass),
+		"store", "--label=hk: "+machine, "service", "hk", "machine", machine)
+	return err
+}
+
+func (SecretService) RemoveCreds(machine string) error {
+	_, err := secretTool(nil, "clear", "service", "hk", "machine", machine)
+	return err
+}
+
+func secretTool(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("secret-tool", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			ee.Stderr = stderr.Bytes()
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("secret-tool %s: %s", args[0], msg)
+			}
+		}
+		return nil, err
+	}
+	return out, nil
+}
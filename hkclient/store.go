@@ -0,0 +1,52 @@
+package hkclient
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// A CredStore saves API credentials. Credentials are keyed by machine name,
+// as returned by Profile.Machine.
+type CredStore interface {
+	// Creds returns the credentials saved for machine, or empty strings if
+	// there are none.
+	Creds(machine string) (user, pass string, err error)
+
+	SaveCreds(machine, user, pass string) error
+	RemoveCreds(machine string) error
+}
+
+// Kinds of credential store accepted by NewCredStore.
+const (
+	NetRcStore         = "netrc"
+	EncryptedStore     = "encrypted"
+	SecretServiceStore = "secret-service"
+)
+
+// NewCredStore returns a credential store of the given kind, which defaults
+// to NetRcStore if empty. passphrase is used to prompt for the passphrase of
+// an EncryptedStore, and is not called for other kinds of store.
+func NewCredStore(kind string, passphrase func() (string, error)) (CredStore, error) {
+	switch kind {
+	case "", NetRcStore:
+		return LoadNetRc()
+	case EncryptedStore:
+		return &EncryptedFile{Path: encryptedFilePath(), Passphrase: passphrase}, nil
+	case SecretServiceStore:
+		return SecretService{}, nil
+	}
+	return nil, fmt.Errorf("unknown credential store %q", kind)
+}
+
+// ProfileCreds returns the credentials saved in s for profile p. Credentials
+// included in apiURL take precedence.
+func ProfileCreds(s CredStore, apiURL *url.URL, p *Profile) (user, pass string, err error) {
+	if apiURL.Host == "" {
+		return "", "", fmt.Errorf("missing API host: %s", apiURL)
+	}
+	if apiURL.User != nil {
+		pw, _ := apiURL.User.Password()
+		return apiURL.User.Username(), pw, nil
+	}
+	return s.Creds(p.Machine(apiURL.Host))
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 )
 
 var cmdInfo = &Command{
@@ -10,6 +9,7 @@ var cmdInfo = &Command{
 	Usage:    "info",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "show app info",
 	Long:     `Info shows general information about the current app.`,
 }
@@ -17,10 +17,13 @@ var cmdInfo = &Command{
 func runInfo(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	app, err := client.AppInfo(mustApp())
 	must(err)
+	if printRaw(app) {
+		return
+	}
 	fmt.Printf("Name:     %s\n", app.Name)
 	fmt.Printf("Owner:    %s\n", app.Owner.Email)
 	fmt.Printf("Region:   %s\n", app.Region.Name)
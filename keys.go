@@ -9,7 +9,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"syscall"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/hkclient"
 )
@@ -22,6 +21,7 @@ var cmdKeys = &Command{
 	Run:      runKeys,
 	Usage:    "keys",
 	Category: "account",
+	Raw:      true,
 	Short:    "list ssh public keys" + extra,
 	Long: `
 Keys lists SSH public keys associated with your Heroku account.
@@ -36,17 +36,17 @@ Examples:
 func runKeys(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	keys, err := client.KeyList(nil)
 	must(err)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	for i := range keys {
-		listRec(w,
+		w.Rec(keys[i],
 			keys[i].Fingerprint,
 			keys[i].Email,
 		)
@@ -72,7 +72,7 @@ It tries these sources for keys, in order:
 func runKeyAdd(cmd *Command, args []string) {
 	if len(args) > 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	if len(args) == 1 {
 		sshPubKeyPath = args[0]
@@ -156,7 +156,7 @@ Examples:
 func runKeyRemove(cmd *Command, args []string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	fingerprint := args[0]
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"os/signal"
 	"regexp"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
@@ -64,7 +65,7 @@ func init() {
 func runLog(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	opts := heroku.LogSessionCreateOpts{}
@@ -106,6 +107,20 @@ func runLog(cmd *Command, args []string) {
 	// colors are disabled globally in main() depending on term.IsTerminal()
 	writer := newColorizer(os.Stdout)
 
+	// Ctrl-C stops tailing; closing the body ends the scan below.
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sigc:
+			resp.Body.Close()
+		case <-done:
+		}
+	}()
+
 	scanner := bufio.NewScanner(resp.Body)
 	scanner.Split(bufio.ScanLines)
 
@@ -28,6 +28,14 @@ type Command struct {
 	Flag     flag.FlagSet
 	NeedsApp bool
 
+	// Plan returns the changes Run would make, for --dry-run. It must
+	// not change anything. Commands without a Plan reject --dry-run.
+	Plan func(cmd *Command, args []string) *plan
+
+	// Raw is set for commands that honor --json and --format. Other
+	// commands reject them, except with --dry-run.
+	Raw bool
+
 	Usage    string // first word is the command name
 	Category string // i.e. "App", "Account", etc.
 	Short    string // `hk help` output
@@ -112,6 +120,7 @@ var commands = []*Command{
 	helpCommands,
 	helpEnviron,
 	helpPlugins,
+	helpOutput,
 	helpMore,
 	helpAbout,
 
@@ -128,17 +137,25 @@ var commands = []*Command{
 	cmdAddonPlans,
 	cmdAddonServices,
 	cmdAPI,
+	cmdAppDiff,
+	cmdApply,
 	cmdAuthorize,
 	cmdCreds,
+	cmdCredsMigrate,
+	cmdDeploySlug,
 	cmdDrains,
 	cmdDrainInfo,
 	cmdDrainAdd,
 	cmdDrainRemove,
+	cmdExport,
 	cmdFeatures,
 	cmdFeatureInfo,
 	cmdFeatureEnable,
 	cmdFeatureDisable,
+	cmdForeach,
+	cmdFork,
 	cmdGet,
+	cmdGuard,
 	cmdKeys,
 	cmdKeyAdd,
 	cmdKeyRemove,
@@ -153,8 +170,13 @@ var commands = []*Command{
 	cmdPgList,
 	cmdPgInfo,
 	cmdPgUnfollow,
+	cmdProfiles,
+	cmdPromote,
 	cmdPsql,
+	cmdRateLimit,
 	cmdRegions,
+	cmdReleaseDiff,
+	cmdShell,
 	cmdSSL,
 	cmdSSLCertAdd,
 	cmdSSLCertRollback,
@@ -173,16 +195,30 @@ var commands = []*Command{
 }
 
 var (
-	flagApp   string
-	client    *heroku.Client
-	pgclient  *postgresql.Client
-	hkAgent   = "hk/" + Version + " (" + runtime.GOOS + "; " + runtime.GOARCH + ")"
-	userAgent = hkAgent + " " + heroku.DefaultUserAgent
+	flagApp     string
+	flagProfile string
+	client      *heroku.Client
+	pgclient    *postgresql.Client
+	profile     *hkclient.Profile
+	hkAgent     = "hk/" + Version + " (" + runtime.GOOS + "; " + runtime.GOARCH + ")"
+	userAgent   = hkAgent + " " + heroku.DefaultUserAgent
 )
 
-func initClients() {
+// initClients sets up the API clients for the selected profile. It returns
+// hkclient.ErrProfileNotFound if that profile has not been saved, in which
+// case the clients are still usable but have no credentials.
+func initClients() error {
 	loadNetrc()
-	suite, err := hkclient.New(nrc, hkAgent)
+	p, err := hkclient.LoadProfile(profileName())
+	if err != nil && err != hkclient.ErrProfileNotFound {
+		printFatal("loading profiles: " + err.Error())
+	}
+	initClientsForProfile(p)
+	return err
+}
+
+func initClientsForProfile(p *hkclient.Profile) {
+	suite, err := hkclient.New(loadCreds(), hkAgent, p)
 	if err != nil {
 		printFatal(err.Error())
 	}
@@ -190,16 +226,34 @@ func initClients() {
 	client = suite.Client
 	pgclient = suite.PgClient
 	apiURL = suite.ApiURL
+	profile = suite.Profile
+}
+
+// profileName returns the name of the selected profile, or "" for the default.
+func profileName() string {
+	if flagProfile != "" {
+		return flagProfile
+	}
+	return os.Getenv("HKPROFILE")
 }
 
 func main() {
 	log.SetFlags(0)
 
-	// make sure command is specified, disallow global args
+	// -P <profile> is the only global flag, and must come first
 	args := os.Args[1:]
+	if len(args) > 1 && (args[0] == "-P" || args[0] == "--profile") {
+		flagProfile = args[1]
+		args = args[2:]
+	} else if len(args) > 0 && strings.HasPrefix(args[0], "--profile=") {
+		flagProfile = strings.TrimPrefix(args[0], "--profile=")
+		args = args[1:]
+	}
+
+	// make sure command is specified, disallow other global args
 	if len(args) < 1 || strings.IndexRune(args[0], '-') == 0 {
 		printUsageTo(os.Stderr)
-		os.Exit(2)
+		exit(2)
 	}
 
 	// Run the update command as early as possible to avoid the possibility of
@@ -215,52 +269,14 @@ func main() {
 		ansi.DisableColors(true)
 	}
 
-	initClients()
-
-	for _, cmd := range commands {
-		if cmd.Name() == args[0] && cmd.Run != nil {
-			defer recoverPanic()
+	if err := initClients(); err != nil && args[0] != cmdLogin.Name() {
+		printFatal("unknown profile %q. Create it with `hk login --profile %s`.", profileName(), profileName())
+	}
 
-			cmd.Flag.SetDisableDuplicates(true) // disallow duplicate flag options
-			if !gitConfigBool("hk.strict-flag-ordering") {
-				cmd.Flag.SetInterspersed(true) // allow flags & non-flag args to mix
-			}
-			cmd.Flag.Usage = func() {
-				cmd.PrintUsage()
-			}
-			if cmd.NeedsApp {
-				cmd.Flag.StringVarP(&flagApp, "app", "a", "", "app name")
-			}
-			if err := cmd.Flag.Parse(args[1:]); err == flag.ErrHelp {
-				cmdHelp.Run(cmdHelp, args[:1])
-				return
-			} else if err != nil {
-				printError(err.Error())
-				os.Exit(2)
-			}
-			if flagApp != "" {
-				if gitRemoteApp, err := appFromGitRemote(flagApp); err == nil {
-					flagApp = gitRemoteApp
-				}
-			}
-			if cmd.NeedsApp {
-				a, err := app()
-				switch {
-				case err == errMultipleHerokuRemotes, err == nil && a == "":
-					msg := "no app specified"
-					if err != nil {
-						msg = err.Error()
-					}
-					printError(msg)
-					cmd.PrintUsage()
-					os.Exit(2)
-				case err != nil:
-					printFatal(err.Error())
-				}
-			}
-			cmd.Run(cmd, cmd.Flag.Args())
-			return
-		}
+	if cmd := findCommand(args[0]); cmd != nil {
+		defer recoverPanic()
+		dispatchCommand(cmd, args[1:])
+		return
 	}
 
 	path := findPlugin(args[0])
@@ -270,12 +286,19 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Possible alternatives: %v\n", strings.Join(g, " "))
 		}
 		fmt.Fprintf(os.Stderr, "Run 'hk help' for usage.\n")
-		os.Exit(2)
+		exit(2)
 	}
 	err := execPlugin(path, args)
 	printFatal("exec error: %s", err)
 }
 
+func resetFlags(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		f.Value.Set(f.DefValue)
+		f.Changed = false
+	})
+}
+
 var rollbarClient = &rollbar.Client{
 	AppName:    "hk",
 	AppVersion: Version,
@@ -283,6 +306,98 @@ var rollbarClient = &rollbar.Client{
 	Token:      "d344db7a09fa481e983694bfa326e6d9",
 }
 
+// findCommand returns the runnable command with the given name, or nil.
+func findCommand(name string) *Command {
+	for _, cmd := range commands {
+		if cmd.Name() == name && cmd.Run != nil {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// dispatchCommand parses the flags in args and runs cmd. It may be called
+// more than once, as in hk shell, so flags are reset to their defaults
+// each time.
+func dispatchCommand(cmd *Command, args []string) {
+	if cmd.Flag.Lookup("json") == nil {
+		cmd.Flag.SetDisableDuplicates(true) // disallow duplicate flag options
+		if !gitConfigBool("hk.strict-flag-ordering") {
+			cmd.Flag.SetInterspersed(true) // allow flags & non-flag args to mix
+		}
+		cmd.Flag.Usage = func() {
+			cmd.PrintUsage()
+		}
+		if cmd.NeedsApp {
+			cmd.Flag.StringVarP(&flagApp, "app", "a", "", "app name")
+		}
+		cmd.Flag.BoolVar(&flagJSON, "json", false, "output JSON")
+		cmd.Flag.StringVar(&flagFormat, "format", "", "output using a Go template")
+		cmd.Flag.BoolVar(&flagDryRun, "dry-run", false, "show changes without making them")
+	}
+
+	// Commands share flag variables such as --confirm and --limit, so
+	// every command's flags are reset, not just cmd's, or a flag given to
+	// one command would carry over into the next. cmd's go last, as the
+	// same variable may have different defaults in different commands.
+	for _, c := range commands {
+		resetFlags(&c.Flag)
+	}
+	resetFlags(&cmd.Flag)
+
+	if err := cmd.Flag.Parse(args); err == flag.ErrHelp {
+		cmdHelp.Run(cmdHelp, []string{cmd.Name()})
+		return
+	} else if err != nil {
+		printError(err.Error())
+		exit(2)
+	}
+	if err := parseOutputFlags(); err != nil {
+		printError(err.Error())
+		exit(2)
+	}
+	if flagDryRun && cmd.Plan == nil {
+		printError("hk %s does not support --dry-run", cmd.Name())
+		exit(2)
+	}
+	if !cmd.Raw && !flagDryRun {
+		switch {
+		case flagJSON:
+			printError("hk %s does not support --json", cmd.Name())
+			exit(2)
+		case flagFormat != "":
+			printError("hk %s does not support --format", cmd.Name())
+			exit(2)
+		}
+	}
+	if flagApp != "" {
+		if gitRemoteApp, err := appFromGitRemote(flagApp); err == nil {
+			flagApp = gitRemoteApp
+		}
+	}
+	// commands with an --apps flag don't need -a if it's given
+	if f := cmd.Flag.Lookup("apps"); cmd.NeedsApp && (f == nil || !f.Changed) {
+		a, err := app()
+		switch {
+		case err == errMultipleHerokuRemotes, err == nil && a == "":
+			msg := "no app specified"
+			if err != nil {
+				msg = err.Error()
+			}
+			printError(msg)
+			cmd.PrintUsage()
+			exit(2)
+		case err != nil:
+			printFatal(err.Error())
+		}
+	}
+	if flagDryRun {
+		cmd.Plan(cmd, cmd.Flag.Args()).Print()
+		return
+	}
+	cmd.Run(cmd, cmd.Flag.Args())
+}
+
 func recoverPanic() {
 	if Version != "dev" {
 		if rec := recover(); rec != nil {
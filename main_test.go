@@ -5,9 +5,21 @@ import (
 	"os"
 	"testing"
 
+	"github.com/heroku/hk/hkclient"
 	"github.com/heroku/hk/postgresql"
 )
 
+// httpTransport returns the *http.Transport underlying rt.
+func httpTransport(rt http.RoundTripper) *http.Transport {
+	if r, ok := rt.(*hkclient.CredsTransport); ok {
+		rt = r.Transport
+	}
+	if r, ok := rt.(*hkclient.RetryTransport); ok {
+		rt = r.Transport
+	}
+	return rt.(*http.Transport)
+}
+
 func TestSSLEnabled(t *testing.T) {
 	initClients()
 
@@ -19,7 +31,7 @@ func TestSSLEnabled(t *testing.T) {
 		// No transport means the client defaults to SSL enabled
 		return
 	}
-	conf := client.HTTP.Transport.(*http.Transport).TLSClientConfig
+	conf := httpTransport(client.HTTP.Transport).TLSClientConfig
 	if conf == nil {
 		// No TLSClientConfig means the client defaults to SSL enabled
 		return
@@ -36,7 +48,7 @@ func TestSSLEnabled(t *testing.T) {
 		// No transport means the pgclient defaults to SSL enabled
 		return
 	}
-	conf = pgclient.HTTP.Transport.(*http.Transport).TLSClientConfig
+	conf = httpTransport(pgclient.HTTP.Transport).TLSClientConfig
 	if conf == nil {
 		// No TLSClientConfig means the pgclient defaults to SSL enabled
 		return
@@ -59,7 +71,7 @@ func TestSSLDisable(t *testing.T) {
 	if client.HTTP.Transport == nil {
 		t.Fatalf("client.HTTP.Transport not set")
 	}
-	conf := client.HTTP.Transport.(*http.Transport).TLSClientConfig
+	conf := httpTransport(client.HTTP.Transport).TLSClientConfig
 	if conf == nil {
 		t.Fatalf("client.HTTP.Transport's TLSClientConfig is nil")
 	}
@@ -73,7 +85,7 @@ func TestSSLDisable(t *testing.T) {
 	if pgclient.HTTP.Transport == nil {
 		t.Fatalf("pgclient.HTTP.Transport not set")
 	}
-	conf = pgclient.HTTP.Transport.(*http.Transport).TLSClientConfig
+	conf = httpTransport(pgclient.HTTP.Transport).TLSClientConfig
 	if conf == nil {
 		t.Fatalf("pgclient.HTTP.Transport's TLSClientConfig is nil")
 	}
@@ -160,3 +172,22 @@ func TestShogunAndHerokuPostgresqlHost(t *testing.T) {
 	os.Setenv("HEROKU_POSTGRESQL_HOST", "")
 	os.Setenv("SHOGUN", "")
 }
+
+func TestDispatchResetsSharedFlags(t *testing.T) {
+	var limit int
+	var confirm string
+	cmd := &Command{
+		Run: func(cmd *Command, args []string) {
+			limit, confirm = flagLimit, flagConfirm
+		},
+		Usage: "test-reset",
+	}
+
+	// as if set by hk apps --limit 5 and hk pg-unfollow --confirm myapp
+	// earlier in hk shell
+	flagLimit, flagConfirm = 5, "myapp"
+	dispatchCommand(cmd, nil)
+	if limit != 0 || confirm != "" {
+		t.Errorf("got --limit %d and --confirm %q, want them reset", limit, confirm)
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -27,26 +26,34 @@ Example:
 func runMaintenance(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	app, err := client.AppInfo(mustApp())
 	must(err)
-	state := "disabled"
-	if app.Maintenance {
-		state = "enabled"
+	fmt.Println(maintenanceState(app.Maintenance))
+}
+
+func maintenanceState(enabled bool) string {
+	if enabled {
+		return "enabled"
 	}
-	fmt.Println(state)
+	return "disabled"
 }
 
 var cmdMaintenanceEnable = &Command{
 	Run:      runMaintenanceEnable,
-	Usage:    "maintenance-enable",
+	Plan:     planMaintenanceEnable,
+	Usage:    "maintenance-enable [--dry-run]",
 	NeedsApp: true,
 	Category: "app",
 	Short:    "enable maintenance mode" + extra,
 	Long: `
 Enables maintenance mode on an app.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Example:
 
     $ hk maintenance-enable
@@ -57,7 +64,7 @@ Example:
 func runMaintenanceEnable(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	newmode := true
 	app, err := client.AppUpdate(mustApp(), &heroku.AppUpdateOpts{Maintenance: &newmode})
@@ -67,13 +74,18 @@ func runMaintenanceEnable(cmd *Command, args []string) {
 
 var cmdMaintenanceDisable = &Command{
 	Run:      runMaintenanceDisable,
-	Usage:    "maintenance-disable",
+	Plan:     planMaintenanceDisable,
+	Usage:    "maintenance-disable [--dry-run]",
 	NeedsApp: true,
 	Category: "app",
 	Short:    "disable maintenance mode" + extra,
 	Long: `
 Disables maintenance mode on an app.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Example:
 
     $ hk maintenance-disable
@@ -84,10 +96,33 @@ Example:
 func runMaintenanceDisable(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	newmode := false
 	app, err := client.AppUpdate(mustApp(), &heroku.AppUpdateOpts{Maintenance: &newmode})
 	must(err)
 	log.Printf("Disabled maintenance mode on %s.", app.Name)
 }
+
+func planMaintenanceEnable(cmd *Command, args []string) *plan {
+	return planMaintenance(cmd, args, true)
+}
+
+func planMaintenanceDisable(cmd *Command, args []string) *plan {
+	return planMaintenance(cmd, args, false)
+}
+
+func planMaintenance(cmd *Command, args []string, enabled bool) *plan {
+	if len(args) != 0 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	appname := mustApp()
+	app, err := client.AppInfo(appname)
+	must(err)
+
+	p := newPlan(appname)
+	p.Change("maintenance", maintenanceState(app.Maintenance), maintenanceState(enabled))
+	p.Request("PATCH", "/apps/"+appname, map[string]bool{"maintenance": enabled})
+	return p
+}
@@ -0,0 +1,674 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var cmdExport = &Command{
+	Run:      runExport,
+	Usage:    "export",
+	NeedsApp: true,
+	Category: "app",
+	Short:    "export an app's setup as a manifest" + extra,
+	Long: `
+Export prints a JSON manifest describing an app's setup: its
+region, stack, formation, add-ons, config vars, domains, log
+drains, features and collaborators. The manifest can be kept in
+version control and applied to an app with 'hk apply'.
+
+Config vars that look like secrets are left out: those set by
+add-ons, those whose names contain words like SECRET, TOKEN,
+PASSWORD or KEY, and URLs that include a password. Drains created
+by add-ons are also left out.
+
+Examples:
+
+    $ hk export > app.json
+
+    $ hk export -a myapp
+    {
+      "name": "myapp",
+      "region": "us",
+      "stack": "cedar",
+      "formation": [
+        {
+          "type": "web",
+          "quantity": 2,
+          "size": "1X"
+        }
+      ],
+      "addons": [
+        {
+          "name": "heroku-postgresql-blue",
+          "plan": "heroku-postgresql:crane"
+        }
+      ],
+      "config": {
+        "RACK_ENV": "production"
+      },
+      "domains": [
+        "www.example.com"
+      ],
+      "drains": [
+        "syslog://logs.example.com:514"
+      ],
+      "features": {
+        "preboot": true
+      },
+      "collaborators": [
+        "user@test.com"
+      ]
+    }
+`,
+}
+
+func runExport(cmd *Command, args []string) {
+	if len(args) != 0 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	live, err := fetchLiveApp(mustApp())
+	must(err)
+	b, err := json.MarshalIndent(live.Manifest(), "", "  ")
+	must(err)
+	fmt.Println(string(b))
+}
+
+var cmdApply = &Command{
+	Run:      runApply,
+	Plan:     planApply,
+	Usage:    "apply [--confirm <name>] [--dry-run] <file>",
+	NeedsApp: true,
+	Category: "app",
+	Short:    "apply a manifest to an app" + extra,
+	Long: `
+Apply changes an app to match a manifest written by 'hk export'.
+It compares the manifest with the app, shows the changes it will
+make, and asks for confirmation before making them. If the app
+doesn't exist, it is created.
+
+Apply only adds and updates; it doesn't remove add-ons, config
+vars, domains, drains or collaborators that are missing from the
+manifest. An add-on is matched by name, or else by service, and
+is moved to the manifest's plan if it's on a different one. The
+region and stack of an existing app can't be changed, so apply
+warns if they differ. Use - as the file to read the manifest
+from stdin.
+
+Options:
+
+    --confirm <name>  confirm applying the changes without a prompt
+    --dry-run         show the changes without making them
+
+Examples:
+
+    $ hk apply -a myapp-staging app.json
+    Changes to myapp-staging:
+    - web=1:1X
+    + web=2:1X
+    + addon.heroku-postgresql=heroku-postgresql:crane
+    + config.RACK_ENV=production
+    + domain=www.example.com
+
+    Requests:
+      PATCH /apps/myapp-staging/formation {"updates":[{"process":"web","quantity":2,"size":"1X"}]}
+      POST /apps/myapp-staging/addons {"plan":"heroku-postgresql:crane"}
+      PATCH /apps/myapp-staging/config-vars {"RACK_ENV":"production"}
+      POST /apps/myapp-staging/domains {"hostname":"www.example.com"}
+    warning: This will make 4 changes to myapp-staging. Please type "myapp-staging" to continue:
+    > myapp-staging
+    Applied 4 changes to myapp-staging.
+
+    $ hk apply --confirm myapp myapp.json
+`,
+}
+
+func init() {
+	cmdApply.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+}
+
+func runApply(cmd *Command, args []string) {
+	appname := mustApp()
+	steps := mustPlanApply(cmd, appname, args)
+	p := stepsPlan(appname, steps)
+	p.Print()
+	if len(steps) == 0 {
+		return
+	}
+
+	warning := fmt.Sprintf("This will make %d changes to %s. Please type %q to continue:", len(steps), appname, appname)
+	mustConfirm(appname, warning, appname)
+	for _, s := range steps {
+		must(s.do())
+	}
+	log.Printf("Applied %d changes to %s.", len(steps), appname)
+}
+
+func planApply(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	return stepsPlan(appname, mustPlanApply(cmd, appname, args))
+}
+
+// mustPlanApply reads the manifest named by args and compares it with the
+// live app, returning the steps needed to converge them.
+func mustPlanApply(cmd *Command, appname string, args []string) []applyStep {
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	m, err := readManifest(args[0])
+	if err != nil {
+		printFatal("reading manifest: %s", err)
+	}
+
+	live, err := fetchLiveApp(appname)
+	if e, ok := err.(heroku.Error); ok && e.Id == "not_found" {
+		live = nil
+	} else {
+		must(err)
+	}
+	steps, warnings := diffManifest(appname, m, live)
+	for _, w := range warnings {
+		printWarning(w)
+	}
+	return steps
+}
+
+func readManifest(name string) (*appManifest, error) {
+	var b []byte
+	var err error
+	if name == "-" {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m appManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// An appManifest is the setup of an app, as written by hk export.
+type appManifest struct {
+	Name          string              `json:"name,omitempty"`
+	Region        string              `json:"region,omitempty"`
+	Stack         string              `json:"stack,omitempty"`
+	Formation     []manifestFormation `json:"formation"`
+	Addons        []manifestAddon     `json:"addons"`
+	Config        map[string]string   `json:"config"`
+	Domains       []string            `json:"domains"`
+	Drains        []string            `json:"drains"`
+	Features      map[string]bool     `json:"features"`
+	Collaborators []string            `json:"collaborators"`
+}
+
+type manifestFormation struct {
+	Type     string `json:"type"`
+	Quantity int    `json:"quantity"`
+	Size     string `json:"size"`
+}
+
+type manifestAddon struct {
+	Name string `json:"name,omitempty"`
+	Plan string `json:"plan"`
+}
+
+// A liveApp is the current state of an app, fetched from the API.
+type liveApp struct {
+	App           *heroku.App
+	Formation     []heroku.Formation
+	Addons        []heroku.Addon
+	Config        map[string]string
+	Domains       []heroku.Domain
+	Drains        []heroku.LogDrain
+	Features      []heroku.AppFeature
+	Collaborators []heroku.Collaborator
+}
+
+// fetchLiveApp fetches the parts of an app described by a manifest, all at
+// once.
+func fetchLiveApp(appname string) (*liveApp, error) {
+	var (
+		live liveApp
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fetch := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := f(); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	fetch(func() (err error) {
+		live.App, err = client.AppInfo(appname)
+		return
+	})
+	fetch(func() (err error) {
+		live.Formation, err = client.FormationList(appname, nil)
+		return
+	})
+	fetch(func() (err error) {
+		live.Addons, err = client.AddonList(appname, nil)
+		return
+	})
+	fetch(func() (err error) {
+		live.Config, err = client.ConfigVarInfo(appname)
+		return
+	})
+	fetch(func() (err error) {
+		live.Domains, err = client.DomainList(appname, nil)
+		return
+	})
+	fetch(func() (err error) {
+		live.Drains, err = client.LogDrainList(appname, nil)
+		return
+	})
+	fetch(func() (err error) {
+		live.Features, err = client.AppFeatureList(appname, nil)
+		return
+	})
+	fetch(func() (err error) {
+		live.Collaborators, err = client.CollaboratorList(appname, nil)
+		return
+	})
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return &live, nil
+}
+
+// secretConfigPattern matches the names of config vars that are likely to
+// hold secrets.
+var secretConfigPattern = regexp.MustCompile(`(?i)SECRET|TOKEN|PASSWORD|PASSWD|KEY|CREDENTIAL|PRIVATE`)
+
+// isSecretConfig reports whether a config var should be left out of a
+// manifest.
+func isSecretConfig(name, value string) bool {
+	if secretConfigPattern.MatchString(name) {
+		return true
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Manifest returns the manifest of the app, without its secrets.
+func (live *liveApp) Manifest() *appManifest {
+	m := &appManifest{
+		Name:          live.App.Name,
+		Region:        live.App.Region.Name,
+		Stack:         live.App.Stack.Name,
+		Formation:     []manifestFormation{},
+		Addons:        []manifestAddon{},
+		Config:        make(map[string]string),
+		Domains:       []string{},
+		Drains:        []string{},
+		Features:      make(map[string]bool),
+		Collaborators: []string{},
+	}
+	for _, f := range live.Formation {
+		m.Formation = append(m.Formation, manifestFormation{f.Type, f.Quantity, f.Size})
+	}
+	sort.Sort(manifestFormationByType(m.Formation))
+
+	addonVars := make(map[string]bool)
+	for _, a := range live.Addons {
+		m.Addons = append(m.Addons, manifestAddon{a.Name, a.Plan.Name})
+		for _, v := range a.ConfigVars {
+			addonVars[v] = true
+		}
+	}
+	sort.Sort(manifestAddonByName(m.Addons))
+
+	for k, v := range live.Config {
+		if !addonVars[k] && !isSecretConfig(k, v) {
+			m.Config[k] = v
+		}
+	}
+	for _, d := range live.Domains {
+		if !isDefaultDomain(d.Hostname) {
+			m.Domains = append(m.Domains, d.Hostname)
+		}
+	}
+	sort.Strings(m.Domains)
+	for _, d := range live.Drains {
+		if d.Addon == nil {
+			m.Drains = append(m.Drains, d.URL)
+		}
+	}
+	sort.Strings(m.Drains)
+	for _, f := range live.Features {
+		m.Features[f.Name] = f.Enabled
+	}
+	for _, c := range live.Collaborators {
+		if c.User.Email != live.App.Owner.Email {
+			m.Collaborators = append(m.Collaborators, c.User.Email)
+		}
+	}
+	sort.Strings(m.Collaborators)
+	return m
+}
+
+// isDefaultDomain reports whether hostname is the domain Heroku gives every
+// app, which can't be added or removed.
+func isDefaultDomain(hostname string) bool {
+	return strings.HasSuffix(hostname, ".herokuapp.com")
+}
+
+// An applyStep is one API request made by hk apply, with the changes it
+// makes.
+type applyStep struct {
+	changes []planChange
+	request planRequest
+	do      func() error
+}
+
+func stepsPlan(appname string, steps []applyStep) *plan {
+	p := newPlan(appname)
+	for _, s := range steps {
+		for _, c := range s.changes {
+			p.Change(c.Name, c.Before, c.After)
+		}
+		p.Request(s.request.Method, s.request.Path, s.request.Body)
+	}
+	return p
+}
+
+// diffManifest returns the steps that make the app match m. live is nil if
+// the app doesn't exist yet. It also returns warnings about differences
+// that can't be applied.
+func diffManifest(appname string, m *appManifest, live *liveApp) (steps []applyStep, warnings []string) {
+	path := "/apps/" + appname
+	if live == nil {
+		opts := heroku.AppCreateOpts{Name: &appname}
+		if m.Region != "" {
+			opts.Region = &m.Region
+		}
+		if m.Stack != "" {
+			opts.Stack = &m.Stack
+		}
+		steps = append(steps, applyStep{
+			changes: []planChange{{"app", "", appname}},
+			request: planRequest{"POST", "/apps", opts},
+			do: func() error {
+				_, err := client.AppCreate(&opts)
+				return err
+			},
+		})
+		live = &liveApp{Config: map[string]string{}}
+		if len(m.Formation) > 0 {
+			warnings = append(warnings, "The formation can't be set until "+appname+" has been deployed.")
+		}
+	} else {
+		if m.Region != "" && m.Region != live.App.Region.Name {
+			warnings = append(warnings, fmt.Sprintf("%s is in the %s region, not %s, and can't be moved.", appname, live.App.Region.Name, m.Region))
+		}
+		if m.Stack != "" && m.Stack != live.App.Stack.Name {
+			warnings = append(warnings, fmt.Sprintf("%s is on the %s stack, not %s.", appname, live.App.Stack.Name, m.Stack))
+		}
+		if s, ok := diffFormation(path, m, live); ok {
+			steps = append(steps, s)
+		}
+	}
+
+	steps = append(steps, diffAddons(appname, path, m, live)...)
+
+	config := make(map[string]*string)
+	var changes []planChange
+	for _, k := range sortedKeys(m.Config) {
+		if v, ok := live.Config[k]; !ok || v != m.Config[k] {
+			val := m.Config[k]
+			config[k] = &val
+			changes = append(changes, planChange{"config." + k, v, val})
+		}
+	}
+	if len(config) > 0 {
+		steps = append(steps, applyStep{
+			changes: changes,
+			request: planRequest{"PATCH", path + "/config-vars", config},
+			do: func() error {
+				_, err := client.ConfigVarUpdate(appname, config)
+				return err
+			},
+		})
+	}
+
+	domains := make(map[string]bool)
+	for _, d := range live.Domains {
+		domains[d.Hostname] = true
+	}
+	for _, hostname := range m.Domains {
+		if domains[hostname] {
+			continue
+		}
+		hostname := hostname
+		steps = append(steps, applyStep{
+			changes: []planChange{{"domain", "", hostname}},
+			request: planRequest{"POST", path + "/domains", map[string]string{"hostname": hostname}},
+			do: func() error {
+				_, err := client.DomainCreate(appname, hostname)
+				return err
+			},
+		})
+	}
+
+	drains := make(map[string]bool)
+	for _, d := range live.Drains {
+		drains[d.URL] = true
+	}
+	for _, drainURL := range m.Drains {
+		if drains[drainURL] {
+			continue
+		}
+		drainURL := drainURL
+		steps = append(steps, applyStep{
+			changes: []planChange{{"drain", "", drainURL}},
+			request: planRequest{"POST", path + "/log-drains", map[string]string{"url": drainURL}},
+			do: func() error {
+				_, err := client.LogDrainCreate(appname, drainURL)
+				return err
+			},
+		})
+	}
+
+	features := make(map[string]bool)
+	for _, f := range live.Features {
+		features[f.Name] = f.Enabled
+	}
+	names := make([]string, 0, len(m.Features))
+	for name := range m.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		enabled, ok := features[name]
+		if ok && enabled == m.Features[name] {
+			continue
+		}
+		name, want := name, m.Features[name]
+		before := ""
+		if ok {
+			before = strconv.FormatBool(enabled)
+		}
+		steps = append(steps, applyStep{
+			changes: []planChange{{"feature." + name, before, strconv.FormatBool(want)}},
+			request: planRequest{"PATCH", path + "/features/" + name, map[string]bool{"enabled": want}},
+			do: func() error {
+				_, err := client.AppFeatureUpdate(appname, name, want)
+				return err
+			},
+		})
+	}
+
+	collaborators := make(map[string]bool)
+	for _, c := range live.Collaborators {
+		collaborators[c.User.Email] = true
+	}
+	for _, email := range m.Collaborators {
+		if collaborators[email] {
+			continue
+		}
+		email := email
+		steps = append(steps, applyStep{
+			changes: []planChange{{"collaborator", "", email}},
+			request: planRequest{"POST", path + "/collaborators", map[string]string{"user": email}},
+			do: func() error {
+				_, err := client.CollaboratorCreate(appname, email, nil)
+				return err
+			},
+		})
+	}
+	return steps, warnings
+}
+
+// diffFormation returns a step that scales the app's process types to the
+// manifest's formation, if any of them differ.
+func diffFormation(path string, m *appManifest, live *liveApp) (applyStep, bool) {
+	current := make(map[string]heroku.Formation)
+	for _, f := range live.Formation {
+		current[f.Type] = f
+	}
+	var (
+		changes []planChange
+		updates []heroku.FormationBatchUpdateOpts
+	)
+	for _, f := range m.Formation {
+		var before string
+		if cur, ok := current[f.Type]; ok {
+			if cur.Quantity == f.Quantity && (f.Size == "" || cur.Size == f.Size) {
+				continue
+			}
+			before = strconv.Itoa(cur.Quantity) + ":" + cur.Size
+		}
+		f := f
+		opt := heroku.FormationBatchUpdateOpts{Process: f.Type, Quantity: &f.Quantity}
+		after := strconv.Itoa(f.Quantity)
+		if f.Size != "" {
+			opt.Size = &f.Size
+			after += ":" + f.Size
+		}
+		updates = append(updates, opt)
+		changes = append(changes, planChange{f.Type, before, after})
+	}
+	if len(updates) == 0 {
+		return applyStep{}, false
+	}
+	appname := strings.TrimPrefix(path, "/apps/")
+	return applyStep{
+		changes: changes,
+		request: planRequest{"PATCH", path + "/formation", map[string]interface{}{"updates": updates}},
+		do: func() error {
+			_, err := client.FormationBatchUpdate(appname, updates)
+			return err
+		},
+	}, true
+}
+
+// diffAddons returns the steps that provision the manifest's add-ons. Each
+// add-on in the manifest is matched with an add-on of the app that has the
+// same name or, failing that, the same service.
+func diffAddons(appname, path string, m *appManifest, live *liveApp) []applyStep {
+	var steps []applyStep
+	matched := make(map[string]bool)
+	byName := make(map[string]heroku.Addon)
+	for _, a := range live.Addons {
+		byName[a.Name] = a
+	}
+	for _, want := range m.Addons {
+		var (
+			cur   heroku.Addon
+			found bool
+		)
+		if a, ok := byName[want.Name]; ok && want.Name != "" && !matched[a.Id] {
+			cur, found = a, true
+		} else {
+			for _, a := range live.Addons {
+				if !matched[a.Id] && addonService(a.Plan.Name) == addonService(want.Plan) {
+					cur, found = a, true
+					break
+				}
+			}
+		}
+
+		plan := want.Plan
+		name := "addon." + addonService(plan)
+		if !found {
+			steps = append(steps, applyStep{
+				changes: []planChange{{name, "", plan}},
+				request: planRequest{"POST", path + "/addons", map[string]string{"plan": plan}},
+				do: func() error {
+					_, err := client.AddonCreate(appname, plan, nil)
+					return err
+				},
+			})
+			continue
+		}
+		matched[cur.Id] = true
+		if cur.Plan.Name == plan {
+			continue
+		}
+		id := cur.Id
+		steps = append(steps, applyStep{
+			changes: []planChange{{name, cur.Plan.Name, plan}},
+			request: planRequest{"PATCH", path + "/addons/" + cur.Name, map[string]string{"plan": plan}},
+			do: func() error {
+				_, err := client.AddonUpdate(appname, id, plan)
+				return err
+			},
+		})
+	}
+	return steps
+}
+
+// addonService returns the service part of a plan name, e.g.
+// "heroku-postgresql" for "heroku-postgresql:crane".
+func addonService(plan string) string {
+	return strings.SplitN(plan, ":", 2)[0]
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type manifestFormationByType []manifestFormation
+
+func (a manifestFormationByType) Len() int           { return len(a) }
+func (a manifestFormationByType) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a manifestFormationByType) Less(i, j int) bool { return a[i].Type < a[j].Type }
+
+type manifestAddonByName []manifestAddon
+
+func (a manifestAddonByName) Len() int           { return len(a) }
+func (a manifestAddonByName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a manifestAddonByName) Less(i, j int) bool { return a[i].Name < a[j].Name }
@@ -1,9 +1,7 @@
 package main
 
 import (
-	"os"
 	"sort"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
@@ -13,6 +11,7 @@ var cmdMembers = &Command{
 	Usage:    "members <orgname>",
 	NeedsApp: false,
 	Category: "members",
+	Raw:      true,
 	Short:    "list member permissions for an organization" + extra,
 	Long: `
 List member permissions for an organization. Members are listed
@@ -27,12 +26,12 @@ Examples:
 }
 
 func runMembers(cmd *Command, args []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	orgname := args[0]
@@ -42,7 +41,7 @@ func runMembers(cmd *Command, args []string) {
 
 	sort.Sort(membersByEmail(orgMembers))
 	for _, oc := range orgMembers {
-		listRec(w,
+		w.Rec(oc,
 			oc.Email,
 			oc.Role,
 		)
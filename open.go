@@ -1,7 +1,5 @@
 package main
 
-import "os"
-
 var cmdOpen = &Command{
 	Run:      runOpen,
 	Usage:    "open",
@@ -14,7 +12,7 @@ var cmdOpen = &Command{
 func runOpen(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	app, err := client.AppInfo(mustApp())
 	must(err)
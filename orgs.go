@@ -1,10 +1,6 @@
 package main
 
 import (
-	"io"
-	"os"
-	"text/tabwriter"
-
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
 
@@ -12,12 +8,13 @@ var cmdOrgs = &Command{
 	Run:      runOrgs,
 	Usage:    "orgs",
 	Category: "orgs",
+	Raw:      true,
 	Short:    "list Heroku orgs",
 	Long:     "Lists Heroku organizations that the user belongs to.",
 }
 
 func runOrgs(cmd *Command, args []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	orgs, err := client.OrganizationList(&heroku.ListRange{Field: "name"})
@@ -25,15 +22,26 @@ func runOrgs(cmd *Command, args []string) {
 	printOrgsList(w, orgs)
 }
 
-func printOrgsList(w io.Writer, orgs []heroku.Organization) {
+func printOrgsList(w *listWriter, orgs []heroku.Organization) {
 	for _, org := range orgs {
-		listRec(w,
+		w.Rec(org,
 			org.Name,
 			org.Role,
 		)
 	}
 }
 
+// orgName returns the org given with -o, or the selected profile's default org.
+func orgName() string {
+	if flagOrgName != "" {
+		return flagOrgName
+	}
+	if profile != nil {
+		return profile.Org
+	}
+	return ""
+}
+
 // Returns true if the app is in an org, and false otherwise.
 func isAppInOrg(app *heroku.OrganizationApp) bool {
 	return app.Organization != nil
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+var helpOutput = &Command{
+	Usage:    "output",
+	Category: "hk",
+	Short:    "machine-readable output",
+	Long: `
+Commands that list or show resources (apps, dynos, releases,
+addons, info, and so on) accept the --json and --format flags. They
+then print the full API representation of each resource instead of
+the abbreviated, column-aligned text meant for humans. Other
+commands reject these flags, except with --dry-run, which prints
+the planned changes in the given format.
+
+With --json, listing commands print a JSON array, even if it is
+empty, and info commands print a single JSON object.
+
+With --format, each resource is printed using the given Go template
+(see http://golang.org/pkg/text/template/), followed by a newline.
+Field names are those of the underlying heroku-go types, e.g. Name,
+State, and UpdatedAt for a dyno. In addition to the standard
+template functions, the following are available:
+
+    json        value encoded as JSON
+    prettyTime  time formatted as in hk's usual output
+    abbrev      string shortened to at most n chars
+
+Examples:
+
+    $ hk dynos --json
+    [
+      {
+        "attach_url": null,
+        "command": "bin/web",
+        ...
+      }
+    ]
+
+    $ hk dynos --format '{{.Name}} {{.State}}'
+    web.1 up
+    web.2 crashed
+
+    $ hk releases --format '{{.Version}} {{.User.Email}} {{prettyTime .CreatedAt}}'
+    41 user@test.com Jun 12 18:28
+    42 user@test.com Jun 13 18:14
+
+    $ hk info --format '{{.Stack.Name}}'
+    cedar
+`,
+}
+
+var (
+	// flagJSON and flagFormat are set by the --json and --format flags,
+	// which are added to every command, but only honored by those with
+	// Raw set.
+	flagJSON   bool
+	flagFormat string
+
+	outputTemplate *template.Template
+)
+
+var outputFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"prettyTime": func(v interface{}) string {
+		switch t := v.(type) {
+		case time.Time:
+			return prettyTime{t}.String()
+		case *time.Time:
+			if t != nil {
+				return prettyTime{*t}.String()
+			}
+		}
+		return ""
+	},
+	"abbrev": func(n int, s string) string {
+		return abbrev(s, n)
+	},
+}
+
+// parseOutputFlags validates the output flags after a command's flags are
+// parsed, and compiles the --format template if there is one.
+func parseOutputFlags() error {
+	if flagFormat == "" {
+		return nil
+	}
+	if flagJSON {
+		return errors.New("--json and --format cannot be used together")
+	}
+	t, err := template.New("format").Funcs(outputFuncs).Parse(flagFormat)
+	if err != nil {
+		return err
+	}
+	outputTemplate = t
+	return nil
+}
+
+// rawOutput returns true if a machine-readable output format was selected, in
+// which case values should not be abbreviated or otherwise prettified.
+func rawOutput() bool {
+	return flagJSON || outputTemplate != nil
+}
+
+// A listWriter collects the records printed by a listing command. Records
+// are written as tab-aligned columns by default, as a JSON array of the
+// underlying values with --json, or one per line with --format.
+type listWriter struct {
+	out  io.Writer
+	tw   *tabwriter.Writer
+	recs []interface{}
+}
+
+func newListWriter() *listWriter {
+	return &listWriter{
+		out:  os.Stdout,
+		tw:   tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0),
+		recs: []interface{}{},
+	}
+}
+
+// Rec adds one record to the list. v is the value printed in machine-readable
+// modes; cols are the columns printed for humans.
+func (w *listWriter) Rec(v interface{}, cols ...interface{}) {
+	switch {
+	case flagJSON:
+		w.recs = append(w.recs, v)
+	case outputTemplate != nil:
+		writeTemplate(w.out, v)
+	default:
+		listRec(w.tw, cols...)
+	}
+}
+
+func (w *listWriter) Flush() {
+	if flagJSON {
+		writeJSON(w.out, w.recs)
+		return
+	}
+	w.tw.Flush()
+}
+
+// printRaw prints v in the selected machine-readable format and returns true.
+// It returns false without printing anything if no such format was selected,
+// in which case the caller should print its usual human-readable output.
+func printRaw(v interface{}) bool {
+	switch {
+	case flagJSON:
+		writeJSON(os.Stdout, v)
+	case outputTemplate != nil:
+		writeTemplate(os.Stdout, v)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeJSON(w io.Writer, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		printFatal("encoding JSON: " + err.Error())
+	}
+	w.Write(append(b, '\n'))
+}
+
+func writeTemplate(w io.Writer, v interface{}) {
+	var b bytes.Buffer
+	if err := outputTemplate.Execute(&b, v); err != nil {
+		printFatal(err.Error())
+	}
+	b.WriteByte('\n')
+	w.Write(b.Bytes())
+}
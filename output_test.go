@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestParseOutputFlags(t *testing.T) {
+	defer func() {
+		flagJSON, flagFormat, outputTemplate = false, "", nil
+	}()
+
+	flagJSON, flagFormat = true, "{{.Name}}"
+	if err := parseOutputFlags(); err == nil {
+		t.Errorf("expected error when both --json and --format are given")
+	}
+
+	flagJSON, flagFormat = false, "{{.Name"
+	if err := parseOutputFlags(); err == nil {
+		t.Errorf("expected error for malformed template")
+	}
+
+	flagFormat = "{{.Name}}"
+	if err := parseOutputFlags(); err != nil {
+		t.Fatal(err)
+	}
+	if !rawOutput() {
+		t.Errorf("expected rawOutput() to be true with --format")
+	}
+}
+
+func TestListWriterFormat(t *testing.T) {
+	defer func() {
+		flagJSON, flagFormat, outputTemplate = false, "", nil
+	}()
+
+	flagFormat = `{{.Name}} {{.State}} {{prettyTime .UpdatedAt | printf "%.3s"}} {{json .Size}}`
+	if err := parseOutputFlags(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := newListWriter()
+	w.out = &buf
+	updated := time.Date(2014, 6, 12, 18, 28, 0, 0, time.UTC)
+	w.Rec(heroku.Dyno{Name: "web.1", State: "up", Size: "1X", UpdatedAt: updated}, "ignored")
+	w.Rec(heroku.Dyno{Name: "web.2", State: "crashed", Size: "2X", UpdatedAt: updated}, "ignored")
+	w.Flush()
+
+	want := "web.1 up Jun \"1X\"\nweb.2 crashed Jun \"2X\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestListWriterJSON(t *testing.T) {
+	defer func() { flagJSON = false }()
+
+	flagJSON = true
+	var buf bytes.Buffer
+	w := newListWriter()
+	w.out = &buf
+	w.Flush()
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("expected empty JSON array, got %q", got)
+	}
+}
+
+func TestListWriterJSONUsesAPITypes(t *testing.T) {
+	defer func() { flagJSON = false }()
+
+	flagJSON = true
+	var buf bytes.Buffer
+	w := newListWriter()
+	w.out = &buf
+	listApp(w, fromApp(heroku.App{Name: "myapp"}))
+	r := newRelease(&heroku.Release{Version: 7})
+	r.Who, r.Commit = "bob", "abc1234"
+	listRelease(w, r)
+	w.Flush()
+
+	out := buf.String()
+	for _, s := range []string{`"name": "myapp"`, `"version": 7`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %s in %s", s, out)
+		}
+	}
+	for _, s := range []string{"OwnerEmail", "Who", "Commit"} {
+		if strings.Contains(out, s) {
+			t.Errorf("unexpected %s in %s", s, out)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/postgresql"
 )
 
 var cmdPgList = &Command{
@@ -17,6 +18,7 @@ var cmdPgList = &Command{
 	Usage:    "pg-list",
 	NeedsApp: true,
 	Category: "pg",
+	Raw:      true,
 	Short:    "list Heroku Postgres databases" + extra,
 	Long: `
 Pg-list shows the name, plan, state, and connection count for
@@ -46,7 +48,7 @@ Examples:
 func runPgList(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	// list all addons
@@ -62,7 +64,9 @@ func runPgList(cmd *Command, args []string) {
 		}
 	}
 	if len(hpgs) == 0 {
-		return // no Heroku Postgres databases to list
+		// no Heroku Postgres databases to list, but --json still prints []
+		newListWriter().Flush()
+		return
 	}
 
 	// fetch app's config concurrently in case we need to resolve DB names
@@ -104,7 +108,7 @@ func runPgList(cmd *Command, args []string) {
 	addonMap := newPgAddonMap(addons, appConf)
 	dbinfos = sortedDBInfoTree(dbinfos, addonMap)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 	printDBTree(w, dbinfos, addonMap)
 }
@@ -114,6 +118,7 @@ var cmdPgInfo = &Command{
 	Usage:    "pg-info [<dbname>]",
 	NeedsApp: true,
 	Category: "pg",
+	Raw:      true,
 	Short:    "show Heroku Postgres database info" + extra,
 	Long: `
 Pg-info shows general information about a Heroku Postgres
@@ -149,7 +154,7 @@ Examples:
 func runPgInfo(cmd *Command, args []string) {
 	if len(args) > 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	var addonName string
@@ -162,6 +167,10 @@ func runPgInfo(cmd *Command, args []string) {
 }
 
 func printPgInfo(name string, dbi fullDBInfo, addonMap *pgAddonMap) {
+	if printRaw(dbi) {
+		return
+	}
+
 	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
 	defer w.Flush()
 
@@ -203,14 +212,21 @@ func printPgInfo(name string, dbi fullDBInfo, addonMap *pgAddonMap) {
 
 var cmdPgUnfollow = &Command{
 	Run:      runPgUnfollow,
-	Usage:    "pg-unfollow <dbname>",
+	Plan:     planPgUnfollow,
+	Usage:    "pg-unfollow [--confirm <app>] [--dry-run] <dbname>",
 	NeedsApp: true,
 	Category: "pg",
 	Short:    "stop a replica postgres database from following" + extra,
 	Long: `
 Pg-unfollow stops a Heroku Postgres database follower from
 following, turning it into a read/write database. The command
-will prompt for confirmation, or accept confirmation via stdin.
+will prompt for confirmation, or accept confirmation via stdin or
+the --confirm flag.
+
+Options:
+
+    --confirm <app>  confirm unfollowing without a prompt
+    --dry-run        show the changes without making them
 
 Examples:
 
@@ -228,29 +244,53 @@ Examples:
 
     $ echo blue | hk pg-unfollow blue
     Unfollowed heroku-postgresql-blue on myapp.
+
+    $ hk pg-unfollow --confirm myapp blue
+    Unfollowed heroku-postgresql-blue on myapp.
 `,
 }
 
+func init() {
+	cmdPgUnfollow.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+}
+
 func runPgUnfollow(cmd *Command, args []string) {
+	appname := mustApp()
+	addonName, db, parentName := mustGetPgUnfollowDB(cmd, appname, args)
+
+	printWarning("%s on %s will permanently stop following %s.", addonName, appname, parentName)
+	warning := fmt.Sprintf("This cannot be undone. Please type %q to continue:", args[0])
+	mustConfirm(appname, warning, args[0])
+
+	must(db.Unfollow())
+	fmt.Printf("Unfollowed %s on %s.\n", addonName, appname)
+}
+
+func planPgUnfollow(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	addonName, db, parentName := mustGetPgUnfollowDB(cmd, appname, args)
+
+	p := newPlan(appname)
+	p.Change(addonName+" following", parentName, "")
+	p.Request("PUT", postgresql.DefaultAPIPath+"/"+db.Id+"/unfollow", nil)
+	return p
+}
+
+// mustGetPgUnfollowDB parses the arguments of hk pg-unfollow, and returns
+// the follower database along with the name of the database it follows.
+func mustGetPgUnfollowDB(cmd *Command, appname string, args []string) (addonName string, db postgresql.DB, parentName string) {
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
-	appname := mustApp()
-	addonName := ensurePrefix(args[0], hpgAddonName()+"-")
+	addonName = ensurePrefix(args[0], hpgAddonName()+"-")
 
 	db, dbi, addonMap := mustGetDBInfoAndAddonMap(addonName, appname)
 	if !dbi.DBInfo.IsFollower() {
 		printFatal("%s is not following another database.", addonName)
 	}
-	parentName := getResolvedInfoValue(dbi.DBInfo, "Following", &addonMap)
-
-	printWarning("%s on %s will permanently stop following %s.", addonName, appname, parentName)
-	warning := fmt.Sprintf("This cannot be undone. Please type %q to continue:", args[0])
-	mustConfirm(warning, args[0])
-
-	must(db.Unfollow())
-	fmt.Printf("Unfollowed %s on %s.\n", addonName, appname)
+	parentName = getResolvedInfoValue(dbi.DBInfo, "Following", &addonMap)
+	return addonName, db, parentName
 }
 
 var commandNamePsql string
@@ -289,7 +329,7 @@ func init() {
 func runPsql(cmd *Command, args []string) {
 	if len(args) > 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 
 	configName := "DATABASE_URL"
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"os"
 	"sort"
 	"strings"
@@ -174,8 +173,8 @@ func mustGetDBInfoAndAddonMap(addonName, appname string) (postgresql.DB, fullDBI
 type fullDBInfo struct {
 	Name     string
 	DBInfo   postgresql.DBInfo
-	Parent   *fullDBInfo
-	Children []*fullDBInfo
+	Parent   *fullDBInfo   `json:"-"`
+	Children []*fullDBInfo `json:"-"`
 }
 
 func (f *fullDBInfo) MaintenanceString() string {
@@ -221,7 +220,7 @@ func sortedDBInfoTree(dbinfos []*fullDBInfo, addonMap pgAddonMap) (result []*ful
 	return
 }
 
-func printDBTree(w io.Writer, dbinfos []*fullDBInfo, addonMap pgAddonMap) {
+func printDBTree(w *listWriter, dbinfos []*fullDBInfo, addonMap pgAddonMap) {
 	for _, info := range dbinfos {
 		name := info.Name
 		if info.Parent != nil {
@@ -232,7 +231,7 @@ func printDBTree(w io.Writer, dbinfos []*fullDBInfo, addonMap pgAddonMap) {
 			dburlMarker = "* "
 		}
 		status, _ := info.DBInfo.Info.GetString("Status")
-		listRec(w,
+		w.Rec(info,
 			dburlMarker+name,
 			info.DBInfo.Plan,
 			strings.ToLower(status)+info.MaintenanceString(),
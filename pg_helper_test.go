@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
 
 func TestHpgOptNames(t *testing.T) {
@@ -113,3 +115,26 @@ func TestDBNameToPgEnv(t *testing.T) {
 		}
 	}
 }
+
+func TestForkDatabaseOpts(t *testing.T) {
+	env := map[string]string{
+		"DATABASE_URL":               "postgres://blue",
+		"HEROKU_POSTGRESQL_BLUE_URL": "postgres://blue",
+	}
+	addon := heroku.Addon{
+		Name:       "heroku-postgresql-blue",
+		ConfigVars: []string{"DATABASE_URL", "HEROKU_POSTGRESQL_BLUE_URL"},
+	}
+	opts, err := forkDatabaseOpts(addon, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"fork": "postgres://blue"}; !reflect.DeepEqual(*opts, want) {
+		t.Errorf("expected %v, got %v", want, *opts)
+	}
+
+	addon.ConfigVars = []string{"DATABASE_URL"}
+	if _, err := forkDatabaseOpts(addon, env); err == nil {
+		t.Error("expected an error for an add-on without a HEROKU_POSTGRESQL_ var")
+	}
+}
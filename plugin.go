@@ -49,6 +49,11 @@ HKHOST
   The hostname (and port, if any) from HEROKU_API_URL, for
   convenience.
 
+HKPROFILE
+
+  The name of the selected account profile. HEROKU_API_URL uses
+  this profile's API URL and credentials.
+
 HKAPP
 
   The name of the heroku app in the current directory, if there is a
@@ -86,6 +91,12 @@ func init() {
 }
 
 func execPlugin(path string, args []string) error {
+	return sysExec(path, args, pluginEnv())
+}
+
+// pluginEnv returns the environment for a plugin, as documented in
+// 'hk help plugins'.
+func pluginEnv() []string {
 	u, err := url.Parse(apiURL)
 	if err != nil {
 		printFatal(err.Error())
@@ -100,10 +111,11 @@ func execPlugin(path string, args []string) error {
 		"HKUSER=" + hkuser,
 		"HKPASS=" + hkpass,
 		"HKHOST=" + u.Host,
+		"HKPROFILE=" + profile.Name,
 		"HKVERSION=" + Version,
 	}
 
-	return sysExec(path, args, append(env, os.Environ()...))
+	return append(env, os.Environ()...)
 }
 
 func findPlugin(name string) (path string) {
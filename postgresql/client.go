@@ -2,6 +2,7 @@ package postgresql
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -198,13 +199,41 @@ func (c *Client) DoReq(req *http.Request, v interface{}) error {
 	return err
 }
 
+// An Error represents a Heroku Postgres API error. Id identifies the kind of
+// error, if the API's response included one, as in the Heroku Platform API.
+type Error struct {
+	error
+	Id         string `json:"id"`
+	Message    string `json:"message"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"status"`
+}
+
+type errorResp struct {
+	Id      string `json:"id"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
 func checkResp(res *http.Response) error {
 	if res.StatusCode/100 != 2 { // 200, 201, 202, etc
 		errb, err := ioutil.ReadAll(res.Body)
 		if err != nil {
-			return fmt.Errorf("unexpected error code=%d", res.StatusCode)
+			msg := fmt.Sprintf("unexpected error code=%d", res.StatusCode)
+			return Error{error: errors.New(msg), Message: msg, StatusCode: res.StatusCode}
+		}
+		var e errorResp
+		if err := json.Unmarshal(errb, &e); err == nil && e.Message != "" {
+			return Error{
+				error:      errors.New(e.Message),
+				Id:         e.Id,
+				Message:    e.Message,
+				URL:        e.URL,
+				StatusCode: res.StatusCode,
+			}
 		}
-		return fmt.Errorf("unexpected status code=%d message=%q", res.StatusCode, string(errb))
+		msg := fmt.Sprintf("unexpected status code=%d message=%q", res.StatusCode, string(errb))
+		return Error{error: errors.New(msg), Message: msg, StatusCode: res.StatusCode}
 	}
 	return nil
 }
@@ -198,11 +198,28 @@ var respTests = []respTest{
 	{newTestResponse(201, `{"code": "OK"}`), nil},
 	{
 		newTestResponse(403, `Access denied`),
-		errors.New("unexpected status code=403 message=\"Access denied\""),
+		Error{
+			error:      errors.New("unexpected status code=403 message=\"Access denied\""),
+			Message:    "unexpected status code=403 message=\"Access denied\"",
+			StatusCode: 403,
+		},
 	},
 	{
 		newTestResponse(401, `Unauthorized`),
-		errors.New("unexpected status code=401 message=\"Unauthorized\""),
+		Error{
+			error:      errors.New("unexpected status code=401 message=\"Unauthorized\""),
+			Message:    "unexpected status code=401 message=\"Unauthorized\"",
+			StatusCode: 401,
+		},
+	},
+	{
+		newTestResponse(404, `{"id": "not_found", "message": "Couldn't find that database."}`),
+		Error{
+			error:      errors.New("Couldn't find that database."),
+			Id:         "not_found",
+			Message:    "Couldn't find that database.",
+			StatusCode: 404,
+		},
 	},
 }
 
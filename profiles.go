@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/hkclient"
+)
+
+var cmdProfiles = &Command{
+	Run:      runProfiles,
+	Usage:    "profiles",
+	Category: "hk",
+	Raw:      true,
+	Short:    "list account profiles" + extra,
+	Long: `
+Lists account profiles. Shows the profile name, the email it is
+logged in as, its API URL, and its default org. The selected
+profile is marked with an asterisk (*).
+
+Select a profile for one command with -P, which must come before
+the command name, or for all commands with the HKPROFILE env var.
+Create a profile, or change its settings, with 'hk login -P'.
+
+Examples:
+
+    $ hk profiles
+    * default  user@test.com  https://api.heroku.com
+      work     me@acme.com    https://api.heroku.com  acme
+
+    $ hk -P work apps
+    myapp  acme  us  Jan 2 12:34
+`,
+}
+
+func runProfiles(cmd *Command, args []string) {
+	if len(args) != 0 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	profiles := mustLoadAllProfiles()
+
+	w := newListWriter()
+	defer w.Flush()
+	for _, p := range profiles {
+		listProfile(w, p)
+	}
+}
+
+// mustLoadAllProfiles returns all saved profiles sorted by name, with the
+// default profile first. The default profile is included even if it was never
+// saved.
+func mustLoadAllProfiles() []*hkclient.Profile {
+	profiles, err := hkclient.LoadProfiles()
+	if err != nil {
+		printFatal("loading profiles: " + err.Error())
+	}
+	hasDefault := false
+	for _, p := range profiles {
+		hasDefault = hasDefault || p.IsDefault()
+	}
+	if !hasDefault {
+		profiles = append(profiles, &hkclient.Profile{Name: hkclient.DefaultProfile})
+	}
+	sort.Sort(profilesByName(profiles))
+	return profiles
+}
+
+// profileAPIURL returns the API URL used by profile p, ignoring HEROKU_API_URL.
+func profileAPIURL(p *hkclient.Profile) string {
+	if p.APIURL != "" {
+		return p.APIURL
+	}
+	return heroku.DefaultAPIURL
+}
+
+func listProfile(w *listWriter, p *hkclient.Profile) {
+	profileURL := profileAPIURL(p)
+	var user string
+	if u, err := url.Parse(profileURL); err == nil {
+		user, _, _ = hkclient.ProfileCreds(loadCreds(), u, p)
+	}
+	marker := "  "
+	if p.Name == profile.Name {
+		marker = "* "
+	}
+	w.Rec(p,
+		marker+p.Name,
+		user,
+		profileURL,
+		p.Org,
+	)
+}
+
+// profilesByName sorts profiles by name, with the default profile first.
+type profilesByName []*hkclient.Profile
+
+func (a profilesByName) Len() int      { return len(a) }
+func (a profilesByName) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a profilesByName) Less(i, j int) bool {
+	return a[i].IsDefault() || !a[j].IsDefault() && a[i].Name < a[j].Name
+}
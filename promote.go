@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var (
+	flagPromoteFrom string
+	flagPromoteTo   string
+)
+
+var cmdPromote = &Command{
+	Run:      runPromote,
+	Usage:    "promote --from <app> --to <app>[,<app>...] [<version>]",
+	Category: "release",
+	Short:    "release another app's slug" + extra,
+	Long: `
+Promote releases the slug of one app onto others, so that the
+exact build tested on staging is shipped to production. It uses
+the source app's latest release, or the given version.
+
+Before releasing, promote checks that the slug has every process
+type that's running on each target app, and doesn't release to
+any of them if one doesn't match.
+
+Options:
+
+    --from <app>         app to promote the slug from
+    --to <app>,...       apps to release the slug to
+    <version>            release of the source app to promote,
+                         e.g. v42 (defaults to the latest)
+
+Examples:
+
+    $ hk promote --from myapp-staging --to myapp
+    Promoted myapp-staging v42 to myapp as v108.
+
+    $ hk promote --from myapp-staging --to myapp-eu,myapp-us v41
+    Promoted myapp-staging v41 to myapp-eu as v77.
+    Promoted myapp-staging v41 to myapp-us as v93.
+`,
+}
+
+func init() {
+	cmdPromote.Flag.StringVar(&flagPromoteFrom, "from", "", "app to promote from")
+	cmdPromote.Flag.StringVar(&flagPromoteTo, "to", "", "apps to promote to")
+}
+
+func runPromote(cmd *Command, args []string) {
+	if flagPromoteFrom == "" || flagPromoteTo == "" || len(args) > 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	var targets []string
+	for _, t := range strings.Split(flagPromoteTo, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+
+	var rel *heroku.Release
+	if len(args) == 1 {
+		var err error
+		rel, err = client.ReleaseInfo(flagPromoteFrom, strings.TrimPrefix(args[0], "v"))
+		must(err)
+	} else {
+		releases, err := client.ReleaseList(flagPromoteFrom, &heroku.ListRange{
+			Field:      "version",
+			Descending: true,
+			Limit:      1,
+		})
+		must(err)
+		if len(releases) == 0 {
+			printFatal("%s has no releases.", flagPromoteFrom)
+		}
+		rel = &releases[0]
+	}
+	if rel.Slug == nil {
+		printFatal("%s v%d has no slug.", flagPromoteFrom, rel.Version)
+	}
+	slug, err := client.SlugInfo(flagPromoteFrom, rel.Slug.Id)
+	must(err)
+
+	// check every target before releasing to any of them
+	failed := false
+	for _, target := range targets {
+		formation, err := client.FormationList(target, nil)
+		must(err)
+		missing, added := compareProcessTypes(slug.ProcessTypes, formation)
+		if len(missing) > 0 {
+			printError("%s runs %s, which the slug doesn't have.", target, strings.Join(missing, ", "))
+			failed = true
+		}
+		if len(added) > 0 {
+			printWarning("%s will have no %s dynos; scale them with hk scale.", target, strings.Join(added, ", "))
+		}
+	}
+	if failed {
+		exit(1)
+	}
+
+	desc := fmt.Sprintf("Promote %s v%d", flagPromoteFrom, rel.Version)
+	for _, target := range targets {
+		r, err := client.ReleaseCreate(target, slug.Id, &heroku.ReleaseCreateOpts{Description: &desc})
+		must(err)
+		log.Printf("Promoted %s v%d to %s as v%d.", flagPromoteFrom, rel.Version, target, r.Version)
+	}
+}
+
+// compareProcessTypes compares the process types of a slug with an app's
+// formation. It returns the types that are running on the app but aren't in
+// the slug, and the types in the slug that the app doesn't have yet.
+func compareProcessTypes(processTypes map[string]string, formation []heroku.Formation) (missing, added []string) {
+	have := make(map[string]bool)
+	for _, f := range formation {
+		have[f.Type] = true
+		if _, ok := processTypes[f.Type]; !ok && f.Quantity > 0 {
+			missing = append(missing, f.Type)
+		}
+	}
+	for t := range processTypes {
+		if !have[t] {
+			added = append(added, t)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(added)
+	return
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestCompareProcessTypes(t *testing.T) {
+	processTypes := map[string]string{"web": "bin/web", "clock": "bin/clock"}
+	formation := []heroku.Formation{
+		{Type: "web", Quantity: 2},
+		{Type: "worker", Quantity: 1},
+		{Type: "release", Quantity: 0},
+	}
+	missing, added := compareProcessTypes(processTypes, formation)
+	if want := []string{"worker"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %q, want %q", missing, want)
+	}
+	if want := []string{"clock"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %q, want %q", added, want)
+	}
+}
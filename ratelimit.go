@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+)
+
+var cmdRateLimit = &Command{
+	Run:      runRateLimit,
+	Usage:    "rate-limit",
+	Category: "account",
+	Raw:      true,
+	Short:    "show API rate limit" + extra,
+	Long: `
+Shows the number of API requests remaining in the current rate
+limit interval. Checking the rate limit does not count towards it.
+
+hk automatically slows down when few requests remain, and retries
+requests that were rate limited. See HKRETRIES in 'hk help environ'.
+
+Example:
+
+    $ hk rate-limit
+    2399
+`,
+}
+
+func runRateLimit(cmd *Command, args []string) {
+	if len(args) != 0 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	rl, err := client.RateLimitInfo()
+	must(err)
+	if printRaw(rl) {
+		return
+	}
+	fmt.Println(rl.Remaining)
+}
@@ -1,14 +1,10 @@
 package main
 
-import (
-	"os"
-	"text/tabwriter"
-)
-
 var cmdRegions = &Command{
 	Run:      runRegions,
 	Usage:    "regions",
 	Category: "misc",
+	Raw:      true,
 	Short:    "list regions" + extra,
 	Long: `
 Lists regions. Shows the region name and description.
@@ -24,16 +20,16 @@ Examples:
 func runRegions(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	regions, err := client.RegionList(nil)
 	must(err)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 
 	for _, r := range regions {
-		listRec(w,
+		w.Rec(r,
 			r.Name,
 			r.Description,
 		)
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var cmdReleaseDiff = &Command{
+	Run:      runReleaseDiff,
+	Usage:    "release-diff <version> <version>",
+	NeedsApp: true,
+	Category: "release",
+	Raw:      true,
+	Short:    "show what changed between releases" + extra,
+	Long: `
+Release-diff shows what changed between two releases: the
+releases made in between, the git commits between the two
+deployed commits (if they're in the local repo), and whether the
+slug, stack or process types changed.
+
+Examples:
+
+    $ hk release-diff v120 v123
+    Releases:
+    v121  bob   Jun 13 18:14  Set DEBUG config vars
+    v122  john  Jun 13 18:31  Deploy 0fda0ae
+    v123  john  Jun 13 18:40  Deploy 3ae20c2
+
+    Commits 0fda0ae..3ae20c2:
+    3ae20c2 John Smith: Fix login redirect
+    9c1b2d4 Bob Jones: Bump rack
+
+    Slug changed.
+    Stack cedar unchanged.
+    Process types:
+    + clock=bin/clock
+    - web=bin/web
+    + web=bin/web -p $PORT
+    - worker=bin/worker
+`,
+}
+
+// A releaseDiff is what changed between two releases.
+type releaseDiff struct {
+	From         int               `json:"from"`
+	To           int               `json:"to"`
+	Releases     []*heroku.Release `json:"releases"`
+	Commits      []string          `json:"commits"`
+	SlugChanged  bool              `json:"slug_changed"`
+	StackBefore  string            `json:"stack_before"`
+	StackAfter   string            `json:"stack_after"`
+	ProcessTypes []planChange      `json:"process_types"`
+
+	releases []*Release // Releases, with their commits, for printing
+}
+
+func runReleaseDiff(cmd *Command, args []string) {
+	appname := mustApp()
+	if len(args) != 2 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	var vers [2]int
+	for i, arg := range args {
+		v, err := strconv.Atoi(strings.TrimPrefix(arg, "v"))
+		if err != nil {
+			printError("bad release version %q", arg)
+			exit(2)
+		}
+		vers[i] = v
+	}
+	if vers[0] > vers[1] {
+		vers[0], vers[1] = vers[1], vers[0]
+	}
+
+	var (
+		rels  [2]*heroku.Release
+		slugs [2]*heroku.Slug
+		errs  [2]error
+		wg    sync.WaitGroup
+	)
+	for i := range vers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rels[i], errs[i] = client.ReleaseInfo(appname, strconv.Itoa(vers[i]))
+			if errs[i] == nil && rels[i].Slug != nil {
+				slugs[i], errs[i] = client.SlugInfo(appname, rels[i].Slug.Id)
+			}
+		}(i)
+	}
+	hrels, err := client.ReleaseList(appname, &heroku.ListRange{
+		Field:   "version",
+		FirstId: strconv.Itoa(vers[0] + 1),
+		LastId:  strconv.Itoa(vers[1]),
+		Max:     1000,
+	})
+	must(err)
+	wg.Wait()
+	for _, err := range errs {
+		must(err)
+	}
+
+	d := releaseDiff{From: vers[0], To: vers[1], Releases: []*heroku.Release{}, Commits: []string{}}
+	for i := range hrels {
+		if v := hrels[i].Version; v > vers[0] && v <= vers[1] {
+			d.releases = append(d.releases, newRelease(&hrels[i]))
+		}
+	}
+	sort.Sort(releasesByVersion(d.releases))
+	for _, r := range d.releases {
+		d.Releases = append(d.Releases, &r.Release)
+	}
+
+	shas := [2]string{releaseSHA(rels[0], slugs[0]), releaseSHA(rels[1], slugs[1])}
+	if shas[0] != "" && shas[1] != "" && shas[0] != shas[1] {
+		commits, err := gitLog(shas[0], shas[1])
+		if err != nil {
+			printWarning("Couldn't list the commits between %s and %s in the local repo.", abbrev(shas[0], 7), abbrev(shas[1], 7))
+		} else if commits != nil {
+			d.Commits = commits
+		}
+	}
+
+	d.ProcessTypes = []planChange{}
+	if slugs[0] != nil && slugs[1] != nil {
+		d.SlugChanged = slugs[0].Id != slugs[1].Id
+		d.StackBefore, d.StackAfter = slugs[0].Stack.Name, slugs[1].Stack.Name
+		d.ProcessTypes = diffProcessTypes(slugs[0].ProcessTypes, slugs[1].ProcessTypes)
+	} else {
+		d.SlugChanged = slugs[0] != slugs[1]
+	}
+
+	if printRaw(d) {
+		return
+	}
+	printReleaseDiff(&d, shas)
+}
+
+func printReleaseDiff(d *releaseDiff, shas [2]string) {
+	fmt.Println("Releases:")
+	gitDescribe(d.releases)
+	abbrevEmailReleases(d.releases)
+	w := newListWriter()
+	for _, r := range d.releases {
+		listRelease(w, r)
+	}
+	w.Flush()
+
+	if len(d.Commits) > 0 {
+		fmt.Println()
+		fmt.Printf("Commits %s..%s:\n", abbrev(shas[0], 7), abbrev(shas[1], 7))
+		for _, c := range d.Commits {
+			fmt.Println(c)
+		}
+	}
+
+	fmt.Println()
+	if d.SlugChanged {
+		fmt.Println("Slug changed.")
+	} else {
+		fmt.Println("Slug unchanged.")
+	}
+	if d.StackBefore != d.StackAfter {
+		fmt.Printf("Stack changed from %s to %s.\n", d.StackBefore, d.StackAfter)
+	} else if d.StackAfter != "" {
+		fmt.Printf("Stack %s unchanged.\n", d.StackAfter)
+	}
+	if len(d.ProcessTypes) > 0 {
+		p := newPlan("")
+		p.Changes = d.ProcessTypes
+		fmt.Println("Process types:")
+		p.printChanges(os.Stdout)
+	}
+}
+
+// releaseSHA returns the git commit a release was built from, from its slug
+// if it has one, or else from its description.
+func releaseSHA(rel *heroku.Release, slug *heroku.Slug) string {
+	if slug != nil && slug.Commit != nil && *slug.Commit != "" {
+		return *slug.Commit
+	}
+	return deploySHA(rel.Description)
+}
+
+// diffProcessTypes returns the process types that were removed, added or
+// changed, in order of name.
+func diffProcessTypes(before, after map[string]string) []planChange {
+	var changes []planChange
+	for _, t := range unionKeys(before, after) {
+		if before[t] != after[t] {
+			changes = append(changes, planChange{t, before[t], after[t]})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffProcessTypes(t *testing.T) {
+	before := map[string]string{"web": "bin/web", "worker": "bin/worker"}
+	after := map[string]string{"web": "bin/web -p $PORT", "clock": "bin/clock"}
+	want := []planChange{
+		{"clock", "", "bin/clock"},
+		{"web", "bin/web", "bin/web -p $PORT"},
+		{"worker", "bin/worker", ""},
+	}
+	if got := diffProcessTypes(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := diffProcessTypes(before, before); len(got) != 0 {
+		t.Errorf("got %v for identical process types, want none", got)
+	}
+}
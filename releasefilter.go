@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var (
+	flagReleasesSince string
+	flagReleasesUntil string
+	flagReleasesUser  string
+	flagReleasesMatch string
+	flagReleasesKind  string
+)
+
+// releaseKinds are the patterns matching the descriptions of each kind of
+// release, for --kind.
+var releaseKinds = map[string]string{
+	"deploy":   `^Deploy `,
+	"config":   ` config vars?$`,
+	"rollback": `^Rollback to `,
+	"addon":    `add-?on`,
+}
+
+// A releaseFilter selects releases by time, user and description. Zero
+// fields match every release.
+type releaseFilter struct {
+	Since, Until time.Time
+	User         string
+	Description  []*regexp.Regexp // all must match
+}
+
+func (f *releaseFilter) empty() bool {
+	return f.Since.IsZero() && f.Until.IsZero() && f.User == "" && len(f.Description) == 0
+}
+
+func (f *releaseFilter) match(r *heroku.Release) bool {
+	switch {
+	case !f.Since.IsZero() && r.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && r.CreatedAt.After(f.Until):
+		return false
+	case f.User != "" && !strings.EqualFold(r.User.Email, f.User):
+		return false
+	}
+	for _, re := range f.Description {
+		if !re.MatchString(r.Description) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseReleaseFilter builds a filter from the flags of hk releases.
+func mustParseReleaseFilter() *releaseFilter {
+	var f releaseFilter
+	var err error
+	now := time.Now()
+	if flagReleasesSince != "" {
+		if f.Since, err = parseTimeArg(flagReleasesSince, now); err != nil {
+			printError("bad --since: %s", err)
+			exit(2)
+		}
+	}
+	if flagReleasesUntil != "" {
+		if f.Until, err = parseTimeArg(flagReleasesUntil, now); err != nil {
+			printError("bad --until: %s", err)
+			exit(2)
+		}
+	}
+	f.User = flagReleasesUser
+
+	// --match and --kind must both match
+	var patterns []string
+	if flagReleasesMatch != "" {
+		patterns = append(patterns, flagReleasesMatch)
+	}
+	if flagReleasesKind != "" {
+		var kinds []string
+		for _, k := range strings.Split(flagReleasesKind, ",") {
+			p, ok := releaseKinds[strings.TrimSpace(k)]
+			if !ok {
+				printError("unknown release kind %q", k)
+				exit(2)
+			}
+			kinds = append(kinds, p)
+		}
+		patterns = append(patterns, strings.Join(kinds, "|"))
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			printError("bad --match: %s", err)
+			exit(2)
+		}
+		f.Description = append(f.Description, re)
+	}
+	return &f
+}
+
+// parseTimeArg parses a time given on the command line: a date, a date and
+// time, an RFC 3339 timestamp, or a duration before now such as 36h, 7d or
+// 2w.
+func parseTimeArg(s string, now time.Time) (time.Time, error) {
+	if n := len(s); n > 1 && (s[n-1] == 'd' || s[n-1] == 'w') {
+		if i, err := strconv.Atoi(s[:n-1]); err == nil {
+			days := i
+			if s[n-1] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't parse %q as a time", s)
+}
+
+// findReleases returns up to limit of an app's most recent releases that
+// match the filter, oldest first. If limit is 0, all matching releases are
+// returned. Pages of the app's history are fetched, newest first, until
+// enough releases are found or the releases are older than f.Since.
+func findReleases(appname string, f *releaseFilter, limit int) ([]*Release, error) {
+	if f.empty() {
+		hrels, err := client.ReleaseList(appname, &heroku.ListRange{
+			Field:      "version",
+			Limit:      limit,
+			Descending: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		rels := make([]*Release, len(hrels))
+		for i := range hrels {
+			rels[i] = newRelease(&hrels[i])
+		}
+		sort.Sort(releasesByVersion(rels))
+		return rels, nil
+	}
+
+	req, err := client.NewRequest("GET", "/apps/"+appname+"/releases", nil)
+	if err != nil {
+		return nil, err
+	}
+	(&heroku.ListRange{Field: "version", Max: heroku.MaxPageSize, Descending: true}).SetHeader(req)
+
+	var rels []*Release
+	it := client.NewListIter(req)
+	var page []heroku.Release
+	for it.Next(&page) {
+		for i := range page {
+			r := &page[i]
+			if !f.Since.IsZero() && r.CreatedAt.Before(f.Since) {
+				sort.Sort(releasesByVersion(rels))
+				return rels, nil
+			}
+			if f.match(r) {
+				rels = append(rels, newRelease(r))
+				if limit > 0 && len(rels) == limit {
+					sort.Sort(releasesByVersion(rels))
+					return rels, nil
+				}
+			}
+		}
+		page = nil
+	}
+	sort.Sort(releasesByVersion(rels))
+	return rels, it.Err()
+}
+
+// findAppsReleases returns up to limit of the most recent releases of
+// several apps that match the filter, oldest first.
+func findAppsReleases(apps []string, f *releaseFilter, limit int) ([]appRelease, error) {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		all  []appRelease
+		errs []error
+	)
+	for _, appname := range apps {
+		wg.Add(1)
+		go func(appname string) {
+			defer wg.Done()
+			rels, err := findReleases(appname, f, limit)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			for _, r := range rels {
+				all = append(all, appRelease{appname, r})
+			}
+		}(appname)
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	sort.Sort(appReleasesByTime(all))
+	if limit > 0 && len(all) > limit {
+		all = all[len(all)-limit:]
+	}
+	return all, nil
+}
+
+type appReleasesByTime []appRelease
+
+func (a appReleasesByTime) Len() int      { return len(a) }
+func (a appReleasesByTime) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a appReleasesByTime) Less(i, j int) bool {
+	return a[i].CreatedAt.Before(a[j].CreatedAt)
+}
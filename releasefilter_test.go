@@ -0,0 +1,66 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestParseTimeArg(t *testing.T) {
+	now := time.Date(2014, 6, 14, 9, 40, 0, 0, time.Local)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"36h", now.Add(-36 * time.Hour)},
+		{"7d", time.Date(2014, 6, 7, 9, 40, 0, 0, time.Local)},
+		{"2w", time.Date(2014, 5, 31, 9, 40, 0, 0, time.Local)},
+		{"2014-06-01", time.Date(2014, 6, 1, 0, 0, 0, 0, time.Local)},
+		{"2014-06-01 12:30", time.Date(2014, 6, 1, 12, 30, 0, 0, time.Local)},
+		{"2014-06-01T12:30:00Z", time.Date(2014, 6, 1, 12, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeArg(tt.in, now)
+		if err != nil {
+			t.Errorf("parseTimeArg(%q): %s", tt.in, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("parseTimeArg(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseTimeArg("last tuesday", now); err == nil {
+		t.Error("expected an error for an unparseable time")
+	}
+}
+
+func TestReleaseFilterMatch(t *testing.T) {
+	rel := heroku.Release{
+		Description: "Deploy 3ae20c2",
+		CreatedAt:   time.Date(2014, 6, 10, 0, 0, 0, 0, time.UTC),
+	}
+	rel.User.Email = "alice@test.com"
+
+	tests := []struct {
+		f    releaseFilter
+		want bool
+	}{
+		{releaseFilter{}, true},
+		{releaseFilter{Since: rel.CreatedAt.Add(-time.Hour)}, true},
+		{releaseFilter{Since: rel.CreatedAt.Add(time.Hour)}, false},
+		{releaseFilter{Until: rel.CreatedAt.Add(-time.Hour)}, false},
+		{releaseFilter{User: "Alice@test.com"}, true},
+		{releaseFilter{User: "bob@test.com"}, false},
+		{releaseFilter{Description: []*regexp.Regexp{regexp.MustCompile(releaseKinds["deploy"])}}, true},
+		{releaseFilter{Description: []*regexp.Regexp{
+			regexp.MustCompile(releaseKinds["deploy"]),
+			regexp.MustCompile("abc"),
+		}}, false},
+		{releaseFilter{Description: []*regexp.Regexp{regexp.MustCompile(releaseKinds["config"])}}, false},
+	}
+	for i, tt := range tests {
+		if got := tt.f.match(&rel); got != tt.want {
+			t.Errorf("test %d: match = %v, want %v", i, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,10 @@ package main
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -17,9 +17,10 @@ var releaseCount int
 
 var cmdReleases = &Command{
 	Run:      runReleases,
-	Usage:    "releases [-n <limit>] [<version>...]",
+	Usage:    "releases [-n <limit>] [--apps <app>,...] [--since <time>] [--until <time>] [--user <email>] [--kind <kind>] [--match <regexp>] [--follow [--exec <command>] [--interval <duration>]] [<version>...]",
 	NeedsApp: true,
 	Category: "release",
+	Raw:      true,
 	Short:    "list releases",
 	Long: `
 Lists releases. Shows the version of the release (e.g. v1), who
@@ -27,7 +28,43 @@ made the release, time of the release, and description.
 
 Options:
 
-    -n <limit>  maximum number of recent releases to display
+    -n <limit>               maximum number of recent releases to
+                             display, or 0 for all of them
+    --apps <app>,...         show releases of these apps, instead of
+                             the one given with -a
+    --since <time>           show releases made since the given time
+    --until <time>           show releases made before the given time
+    --user <email>           show releases made by the given user
+    --kind <kind>,...        show releases of the given kinds: deploy,
+                             config, rollback or addon
+    --match <regexp>         show releases whose descriptions match a
+                             regular expression
+    --follow                 wait for new releases and show them as
+                             they're made, until interrupted
+    --exec <command>         run a shell command for each new release,
+                             with details of the release in env vars
+                             (see below)
+    --interval <duration>    how often to check for new releases
+                             (default 10s)
+
+A time is a date (2014-06-14), a date and time (2014-06-14 09:40),
+an RFC 3339 timestamp, or a duration before now, such as 36h, 7d
+or 2w. If --since is given, all matching releases since then are
+shown unless -n is also given; otherwise the filters look back
+through the app's history for the most recent n matches.
+
+When there are several apps, each release is shown with the app's
+name. With --follow, each new release is printed as it's made.
+With --json, each is printed as a JSON object on its own line.
+The --exec command is run with these env vars set:
+
+    HK_RELEASE_APP          the app's name
+    HK_RELEASE_VERSION      the release version, e.g. 43
+    HK_RELEASE_ID           the release's unique identifier
+    HK_RELEASE_USER         email address of the user who released
+    HK_RELEASE_DESCRIPTION  description of the release
+    HK_RELEASE_CREATED_AT   when the release was made, in RFC 3339
+                            format
 
 Examples:
 
@@ -43,35 +80,73 @@ Examples:
     $ hk releases 1 3
     v1  bob@test.com  Jun 12 18:28  Deploy 3ae20c2
     v3  john@me.com   Jun 13 18:31  Rollback to v2
+
+    $ hk releases --apps myapp,myapp-staging --user alice@test.com --since 7d --kind deploy
+    myapp-staging  v40  alice  Jun 10 11:02  Deploy 9e8d7c6
+    myapp          v41  alice  Jun 11 15:17  Deploy 9e8d7c6
+
+    $ hk releases --follow --apps myapp,myapp-staging
+    myapp-staging  v44  bob@test.com  Jun 14 09:02  Deploy 1c2d3e4
+    myapp          v43  bob@test.com  Jun 14 09:40  Deploy 1c2d3e4
+
+    $ hk releases --follow --json --exec 'notify "$HK_RELEASE_APP v$HK_RELEASE_VERSION"'
+    {"app":"myapp","version":43,"description":"Deploy 1c2d3e4",...}
 `,
 }
 
 func init() {
 	cmdReleases.Flag.IntVarP(&releaseCount, "number", "n", 20, "max number of recent releases to display")
+	cmdReleases.Flag.BoolVar(&flagReleasesFollow, "follow", false, "show new releases as they're made")
+	cmdReleases.Flag.StringVar(&flagApps, "apps", "", "comma-separated app names")
+	cmdReleases.Flag.StringVar(&flagReleasesSince, "since", "", "show releases since a time")
+	cmdReleases.Flag.StringVar(&flagReleasesUntil, "until", "", "show releases before a time")
+	cmdReleases.Flag.StringVar(&flagReleasesUser, "user", "", "show releases by a user")
+	cmdReleases.Flag.StringVar(&flagReleasesKind, "kind", "", "show releases of these kinds")
+	cmdReleases.Flag.StringVar(&flagReleasesMatch, "match", "", "show releases whose descriptions match")
+	cmdReleases.Flag.StringVar(&flagReleasesExec, "exec", "", "command to run for each new release")
+	cmdReleases.Flag.DurationVar(&flagReleasesInterval, "interval", 10*time.Second, "how often to check for new releases")
 }
 
 func runReleases(cmd *Command, versions []string) {
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	if flagReleasesFollow {
+		if len(versions) > 0 || flagReleasesInterval <= 0 {
+			cmd.PrintUsage()
+			exit(2)
+		}
+		followReleases()
+		return
+	}
+	if flagReleasesExec != "" {
+		printError("--exec can only be used with --follow")
+		exit(2)
+	}
+	w := newListWriter()
 	defer w.Flush()
-	listReleases(w, versions)
+	filter := mustParseReleaseFilter()
+	limit := releaseCount
+	if !filter.Since.IsZero() && !cmd.Flag.Lookup("number").Changed {
+		limit = 0
+	}
+	if apps := appsFlag(); len(apps) > 0 {
+		if len(versions) > 0 {
+			cmd.PrintUsage()
+			exit(2)
+		}
+		listAppsReleases(w, apps, filter, limit)
+		return
+	}
+	listReleases(w, versions, filter, limit)
 }
 
-func listReleases(w io.Writer, versions []string) {
+func listReleases(w *listWriter, versions []string, filter *releaseFilter, limit int) {
 	appname := mustApp()
 	if len(versions) == 0 {
-		hrels, err := client.ReleaseList(appname, &heroku.ListRange{
-			Field:      "version",
-			Max:        releaseCount,
-			Descending: true,
-		})
+		rels, err := findReleases(appname, filter, limit)
 		must(err)
-		rels := make([]*Release, len(hrels))
-		for i := range hrels {
-			rels[i] = newRelease(&hrels[i])
-		}
-		sort.Sort(releasesByVersion(rels))
 		gitDescribe(rels)
-		abbrevEmailReleases(rels)
+		if !rawOutput() {
+			abbrevEmailReleases(rels)
+		}
 		for _, r := range rels {
 			listRelease(w, r)
 		}
@@ -99,19 +174,43 @@ func listReleases(w io.Writer, versions []string) {
 		case err := <-errch:
 			printFatal(err.Error())
 		case rel := <-relch:
-			if rel != nil {
+			if rel != nil && filter.match(rel) {
 				rels = append(rels, newRelease(rel))
 			}
 		}
 	}
 	sort.Sort(releasesByVersion(rels))
 	gitDescribe(rels)
-	abbrevEmailReleases(rels)
+	if !rawOutput() {
+		abbrevEmailReleases(rels)
+	}
 	for _, r := range rels {
 		listRelease(w, r)
 	}
 }
 
+func listAppsReleases(w *listWriter, apps []string, filter *releaseFilter, limit int) {
+	all, err := findAppsReleases(apps, filter, limit)
+	must(err)
+	rels := make([]*Release, len(all))
+	for i, r := range all {
+		rels[i] = r.Release
+	}
+	gitDescribe(rels)
+	if !rawOutput() {
+		abbrevEmailReleases(rels)
+	}
+	for _, r := range all {
+		w.Rec(r.raw(),
+			r.App,
+			fmt.Sprintf("v%d", r.Version),
+			abbrev(r.Who, 10),
+			prettyTime{r.CreatedAt},
+			releaseDescription(r.Release),
+		)
+	}
+}
+
 func abbrevEmailReleases(rels []*Release) {
 	domains := make(map[string]int)
 	for _, r := range rels {
@@ -133,19 +232,28 @@ func abbrevEmailReleases(rels []*Release) {
 	}
 }
 
-func listRelease(w io.Writer, r *Release) {
+func listRelease(w *listWriter, r *Release) {
+	w.Rec(&r.Release, releaseCols(r)...)
+}
+
+// releaseCols returns the columns of a release in hk's usual listing.
+func releaseCols(r *Release) []interface{} {
+	return []interface{}{
+		fmt.Sprintf("v%d", r.Version),
+		abbrev(r.Who, 10),
+		prettyTime{r.CreatedAt},
+		releaseDescription(r),
+	}
+}
+
+func releaseDescription(r *Release) string {
 	desc := r.Description
 	// add the git tag to the description if it's not a hash (and thus isn't
 	// included already)
 	if r.Commit != "" && !strings.Contains(r.Description, r.Commit) {
 		desc += " (" + abbrev(r.Commit, 12) + ")"
 	}
-	listRec(w,
-		fmt.Sprintf("v%d", r.Version),
-		abbrev(r.Who, 10),
-		prettyTime{r.CreatedAt},
-		desc,
-	)
+	return desc
 }
 
 type releasesByVersion []*Release
@@ -163,6 +271,7 @@ var cmdReleaseInfo = &Command{
 	Usage:    "release-info <version>",
 	NeedsApp: true,
 	Category: "release",
+	Raw:      true,
 	Short:    "show release info",
 	Long: `
 release-info shows detailed information about a release.
@@ -183,11 +292,14 @@ func runReleaseInfo(cmd *Command, args []string) {
 	appname := mustApp()
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	ver := strings.TrimPrefix(args[0], "v")
 	rel, err := client.ReleaseInfo(appname, ver)
 	must(err)
+	if printRaw(rel) {
+		return
+	}
 
 	fmt.Printf("Version:  v%d\n", rel.Version)
 	fmt.Printf("By:       %s\n", rel.User.Email)
@@ -199,32 +311,181 @@ func runReleaseInfo(cmd *Command, args []string) {
 	}
 }
 
+var (
+	flagRollbackWait  bool
+	flagRollbackGrace time.Duration
+)
+
 var cmdRollback = &Command{
 	Run:      runRollback,
-	Usage:    "rollback <version>",
+	Plan:     planRollback,
+	Usage:    "rollback [--confirm <name>] [--wait [--grace <duration>]] [--dry-run] <version>",
 	NeedsApp: true,
 	Category: "release",
+	Raw:      true,
 	Short:    "roll back to a previous release",
 	Long: `
 Rollback re-releases an app at an older version. This action
 creates a new release based on the older release, then restarts
 the app's dynos on the new release.
 
+Before rolling back, hk shows the release being rolled back to
+and the releases that will be undone. Rolling back a production
+app must be confirmed; an app is considered production if its
+name matches one of the glob patterns in the hk.production-apps
+git config setting (comma-separated), or by default if its name
+ends in -prod or -production.
+
+With --wait, hk waits until all of the app's dynos are up on the
+new release, then keeps watching them for a grace period. It
+exits with status 1 if any of them crash.
+
+Options:
+
+    --confirm <name>     confirm rolling back a production app
+                         without a prompt
+    --wait               wait for the dynos to be up on the new
+                         release
+    --grace <duration>   how long to watch the dynos after they're
+                         up, with --wait (default 30s)
+    --dry-run            show the changes without making them
+
 Examples:
 
     $ hk rollback v4
-    Rolled back myapp to v4 as v7.
+    Rolling back myapp-staging to:
+    v4  bob   Jun 12 18:28  Deploy 3ae20c2 (v1.2.0)
+
+    This undoes:
+    v5  john  Jun 13 18:14  Set DEBUG config vars
+    v6  john  Jun 13 18:31  Deploy 0fda0ae
+    Rolled back myapp-staging to v4 as v7.
+
+    $ hk rollback --confirm myapp-prod --wait v4
+    Rolling back myapp-prod to:
+    v4  bob   Jun 12 18:28  Deploy 3ae20c2 (v1.2.0)
+
+    This undoes:
+    v5  john  Jun 13 18:31  Deploy 0fda0ae
+    Rolled back myapp-prod to v4 as v6.
+    All dynos are up on v6; watching them for 30s.
+    Dynos stayed up on v6.
+
+    $ hk rollback --dry-run v4
+    Changes to myapp:
+    - release=v6 Deploy 0fda0ae
+    + release=v7 Rollback to v4
+
+    Requests:
+      POST /apps/myapp/releases {"release":"4"}
 `,
 }
 
+func init() {
+	cmdRollback.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+	cmdRollback.Flag.BoolVar(&flagRollbackWait, "wait", false, "wait for dynos to be up")
+	cmdRollback.Flag.DurationVar(&flagRollbackGrace, "grace", 30*time.Second, "how long to watch dynos with --wait")
+}
+
 func runRollback(cmd *Command, args []string) {
 	appname := mustApp()
-	if len(args) != 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
+	ver := mustParseRollbackArgs(cmd, args)
+
+	target, err := client.ReleaseInfo(appname, ver)
+	must(err)
+	hrels, err := client.ReleaseList(appname, &heroku.ListRange{
+		Field:   "version",
+		FirstId: strconv.Itoa(target.Version + 1),
+		Max:     1000,
+	})
+	must(err)
+	var undone []*Release
+	for i := range hrels {
+		if hrels[i].Version > target.Version {
+			undone = append(undone, newRelease(&hrels[i]))
+		}
 	}
-	ver := strings.TrimPrefix(args[0], "v")
+	printRollbackPreview(appname, newRelease(target), undone)
+
+	if isProductionApp(appname) {
+		warning := fmt.Sprintf("%s is a production app. Please type %q to roll it back:", appname, appname)
+		mustConfirm(appname, warning, appname)
+	}
+
 	rel, err := client.ReleaseRollback(appname, ver)
 	must(err)
 	log.Printf("Rolled back %s to v%s as v%d.\n", appname, ver, rel.Version)
+
+	if flagRollbackWait {
+		if err := waitForRelease(appname, rel.Version, flagRollbackGrace); err != nil {
+			printFatal(err.Error())
+		}
+		log.Printf("Dynos stayed up on v%d.", rel.Version)
+	}
+}
+
+// printRollbackPreview shows the release an app is being rolled back to,
+// and the releases the rollback undoes. With --json or --format, they're
+// printed as a single object.
+func printRollbackPreview(appname string, target *Release, undone []*Release) {
+	sort.Sort(releasesByVersion(undone))
+	if rawOutput() {
+		preview := struct {
+			Target *heroku.Release   `json:"target"`
+			Undone []*heroku.Release `json:"undone"`
+		}{&target.Release, []*heroku.Release{}}
+		for _, r := range undone {
+			preview.Undone = append(preview.Undone, &r.Release)
+		}
+		printRaw(preview)
+		return
+	}
+
+	gitDescribe(append([]*Release{target}, undone...))
+	abbrevEmailReleases(append([]*Release{target}, undone...))
+	fmt.Printf("Rolling back %s to:\n", appname)
+	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	listRec(w, releaseCols(target)...)
+	w.Flush()
+	if len(undone) > 0 {
+		fmt.Println()
+		fmt.Println("This undoes:")
+		for _, r := range undone {
+			listRec(w, releaseCols(r)...)
+		}
+		w.Flush()
+	}
+}
+
+func planRollback(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	ver := mustParseRollbackArgs(cmd, args)
+
+	// make sure the target exists, as the API would
+	_, err := client.ReleaseInfo(appname, ver)
+	must(err)
+	current, err := client.ReleaseList(appname, &heroku.ListRange{
+		Field:      "version",
+		Descending: true,
+		Limit:      1,
+	})
+	must(err)
+
+	p := newPlan(appname)
+	before, next := "", 1
+	if len(current) > 0 {
+		before = fmt.Sprintf("v%d %s", current[0].Version, current[0].Description)
+		next = current[0].Version + 1
+	}
+	p.Change("release", before, fmt.Sprintf("v%d Rollback to v%s", next, ver))
+	p.Request("POST", "/apps/"+appname+"/releases", map[string]string{"release": ver})
+	return p
+}
+
+func mustParseRollbackArgs(cmd *Command, args []string) (ver string) {
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	return strings.TrimPrefix(args[0], "v")
 }
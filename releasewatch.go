@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var (
+	flagReleasesFollow   bool
+	flagReleasesExec     string
+	flagReleasesInterval time.Duration
+)
+
+// An appRelease is a release of a particular app, as shown by hk releases
+// --follow.
+type appRelease struct {
+	App string
+	*Release
+}
+
+// raw returns the release as printed with --json and --format: the API's
+// representation of it, along with the app's name.
+func (r appRelease) raw() interface{} {
+	return struct {
+		App string `json:"app"`
+		*heroku.Release
+	}{r.App, &r.Release.Release}
+}
+
+// followReleases polls the apps for new releases until interrupted, printing
+// each one and running the --exec command for it.
+func followReleases() {
+	apps := appsFlag()
+	if len(apps) == 0 {
+		apps = []string{mustApp()}
+	}
+
+	// start from each app's latest release
+	latest := make(map[string]int)
+	for _, appname := range apps {
+		rels, err := client.ReleaseList(appname, &heroku.ListRange{
+			Field:      "version",
+			Descending: true,
+			Limit:      1,
+		})
+		must(err)
+		if len(rels) > 0 {
+			latest[appname] = rels[0].Version
+		}
+	}
+
+	width := 0
+	for _, appname := range apps {
+		if len(appname) > width {
+			width = len(appname)
+		}
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	for {
+		select {
+		case <-sigc:
+			return
+		case <-time.After(flagReleasesInterval):
+		}
+		for _, appname := range apps {
+			rels, err := newReleases(appname, latest[appname])
+			if err != nil {
+				printWarning("Couldn't check %s for new releases: %s", appname, err)
+				continue
+			}
+			for _, r := range rels {
+				latest[appname] = r.Version
+				printFollowedRelease(appRelease{appname, r}, width)
+				if flagReleasesExec != "" {
+					execReleaseHook(flagReleasesExec, appname, r)
+				}
+			}
+		}
+	}
+}
+
+// newReleases returns an app's releases after the given version, oldest
+// first.
+func newReleases(appname string, after int) ([]*Release, error) {
+	hrels, err := client.ReleaseList(appname, newReleasesRange(after))
+	if err != nil {
+		return nil, err
+	}
+	rels := releasesAfter(hrels, after)
+	gitDescribe(rels)
+	return rels, nil
+}
+
+// newReleasesRange returns the range of releases to fetch to find those
+// after the given version. If there were none, the app's latest releases
+// are fetched.
+func newReleasesRange(after int) *heroku.ListRange {
+	if after == 0 {
+		return &heroku.ListRange{Field: "version", Descending: true, Max: 1000}
+	}
+	return &heroku.ListRange{
+		Field:   "version",
+		FirstId: strconv.Itoa(after + 1),
+		Max:     1000,
+	}
+}
+
+// releasesAfter returns the releases after the given version, oldest first.
+func releasesAfter(hrels []heroku.Release, after int) []*Release {
+	var rels []*Release
+	for i := range hrels {
+		if hrels[i].Version > after {
+			rels = append(rels, newRelease(&hrels[i]))
+		}
+	}
+	sort.Sort(releasesByVersion(rels))
+	return rels
+}
+
+func printFollowedRelease(r appRelease, width int) {
+	if flagJSON {
+		// one object per line, so the output can be piped
+		b, err := json.Marshal(r.raw())
+		must(err)
+		fmt.Println(string(b))
+		return
+	}
+	if outputTemplate != nil {
+		writeTemplate(os.Stdout, r.raw())
+		return
+	}
+	fmt.Printf("%-*s  v%d  %s  %s  %s\n", width, r.App, r.Version, r.User.Email, prettyTime{r.CreatedAt}, releaseDescription(r.Release))
+}
+
+// execReleaseHook runs a shell command with the details of a release in its
+// environment. A failing command is reported, but doesn't stop hk.
+func execReleaseHook(command, appname string, r *Release) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), releaseHookEnv(appname, r)...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		printWarning("--exec command failed for %s v%d: %s", appname, r.Version, err)
+	}
+}
+
+func releaseHookEnv(appname string, r *Release) []string {
+	return []string{
+		"HK_RELEASE_APP=" + appname,
+		"HK_RELEASE_VERSION=" + strconv.Itoa(r.Version),
+		"HK_RELEASE_ID=" + r.Id,
+		"HK_RELEASE_USER=" + r.User.Email,
+		"HK_RELEASE_DESCRIPTION=" + r.Description,
+		"HK_RELEASE_CREATED_AT=" + r.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestReleaseHookEnv(t *testing.T) {
+	r := newRelease(&heroku.Release{
+		Id:          "abc",
+		Version:     43,
+		Description: "Deploy 1c2d3e4",
+		CreatedAt:   time.Date(2014, 6, 14, 9, 40, 0, 0, time.UTC),
+	})
+	r.User.Email = "bob@test.com"
+	want := []string{
+		"HK_RELEASE_APP=myapp",
+		"HK_RELEASE_VERSION=43",
+		"HK_RELEASE_ID=abc",
+		"HK_RELEASE_USER=bob@test.com",
+		"HK_RELEASE_DESCRIPTION=Deploy 1c2d3e4",
+		"HK_RELEASE_CREATED_AT=2014-06-14T09:40:00Z",
+	}
+	if got := releaseHookEnv("myapp", r); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReleasesAfter(t *testing.T) {
+	var hrels []heroku.Release
+	for _, v := range []int{12, 9, 11, 10, 13} {
+		hrels = append(hrels, heroku.Release{Version: v})
+	}
+	var got []int
+	for _, r := range releasesAfter(hrels, 10) {
+		got = append(got, r.Version)
+	}
+	if want := []int{11, 12, 13}; !reflect.DeepEqual(got, want) {
+		t.Errorf("releasesAfter(10) = %v, want %v", got, want)
+	}
+	if rels := releasesAfter(hrels, 13); len(rels) != 0 {
+		t.Errorf("releasesAfter(13) = %d releases, want none", len(rels))
+	}
+}
+
+func TestNewReleasesRange(t *testing.T) {
+	if r := newReleasesRange(0); r.FirstId != "" || !r.Descending {
+		t.Errorf("newReleasesRange(0) = %+v, want the latest releases", r)
+	}
+	if r := newReleasesRange(42); r.FirstId != "43" || r.Descending {
+		t.Errorf("newReleasesRange(42) = %+v, want releases from 43", r)
+	}
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"log"
-	"os"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
 
 var cmdRename = &Command{
 	Run:      runRename,
-	Usage:    "rename <oldname> <newname>",
+	Plan:     planRename,
+	Usage:    "rename [--dry-run] <oldname> <newname>",
 	Category: "app",
 	Short:    "rename an app",
 	Long: `
 Rename renames a heroku app.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Example:
 
     $ hk rename myapp myapp2
@@ -22,11 +26,7 @@ Example:
 }
 
 func runRename(cmd *Command, args []string) {
-	if len(args) != 2 {
-		cmd.PrintUsage()
-		os.Exit(2)
-	}
-	oldname, newname := args[0], args[1]
+	oldname, newname := mustParseRenameArgs(cmd, args)
 	app, err := client.AppUpdate(oldname, &heroku.AppUpdateOpts{Name: &newname})
 	must(err)
 	log.Printf("Renamed %s to %s.", oldname, app.Name)
@@ -34,3 +34,22 @@ func runRename(cmd *Command, args []string) {
 	// should we automatically update the remote if they specify an app
 	// or via mustApp + conditional logic - RM
 }
+
+func planRename(cmd *Command, args []string) *plan {
+	oldname, newname := mustParseRenameArgs(cmd, args)
+	app, err := client.AppInfo(oldname)
+	must(err)
+
+	p := newPlan(app.Name)
+	p.Change("name", app.Name, newname)
+	p.Request("PATCH", "/apps/"+oldname, map[string]string{"name": newname})
+	return p
+}
+
+func mustParseRenameArgs(cmd *Command, args []string) (oldname, newname string) {
+	if len(args) != 2 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	return args[0], args[1]
+}
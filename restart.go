@@ -1,14 +1,26 @@
 package main
 
 import (
+	"fmt"
 	"log"
-	"os"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+var (
+	flagRestartRolling bool
+	flagRestartBatch   int
+	flagRestartPause   time.Duration
+	flagRestartCrashed bool
 )
 
 var cmdRestart = &Command{
 	Run:      runRestart,
-	Usage:    "restart [<type or name>]",
+	Plan:     planRestart,
+	Usage:    "restart [--crashed | --rolling [--batch <n>] [--pause <duration>]] [--dry-run] [<type or name>]",
 	NeedsApp: true,
 	Category: "dyno",
 	Short:    "restart dynos (or stop a dyno started with 'hk run')",
@@ -16,6 +28,22 @@ var cmdRestart = &Command{
 Restart all app dynos, all dynos of a specific type, or a single dyno. If used
 on a dyno started using 'hk run' this will effectively stop it.
 
+With --crashed, only dynos that have crashed are restarted. To keep
+restarting dynos as they crash, see 'hk guard'.
+
+With --rolling, the dynos of a type are restarted one at a time, or a
+batch at a time, instead of all at once. Each batch must be up
+again before the next is restarted. If a restarted dyno crashes, or
+doesn't come up within 5 minutes, the restart stops there.
+
+Options:
+
+    --crashed           restart only crashed dynos
+    --rolling           restart the dynos of a type a batch at a time
+    --batch <n>         number of dynos per batch (default 1)
+    --pause <duration>  time to wait after each batch is up, e.g. 30s
+    --dry-run           show the dynos that would be restarted
+
 Examples:
 
     $ hk restart
@@ -26,14 +54,35 @@ Examples:
 
     $ hk restart web.1
     Restarted web.1 dyno on myapp.
+
+    $ hk restart --crashed worker
+    Restarted worker.2 (crashed 12m ago).
+    Restarted worker.4 (crashed 3m ago).
+
+    $ hk restart --rolling --pause 10s web
+    Restarting web.1... up.
+    Restarting web.2... up.
+    Restarted web dynos on myapp one at a time.
 `,
 }
 
+func init() {
+	cmdRestart.Flag.BoolVar(&flagRestartRolling, "rolling", false, "restart a batch of dynos at a time")
+	cmdRestart.Flag.IntVar(&flagRestartBatch, "batch", 1, "number of dynos per batch")
+	cmdRestart.Flag.DurationVar(&flagRestartPause, "pause", 0, "time to wait between batches")
+	cmdRestart.Flag.BoolVar(&flagRestartCrashed, "crashed", false, "restart only crashed dynos")
+}
+
 func runRestart(cmd *Command, args []string) {
 	appname := mustApp()
-	if len(args) > 1 {
-		cmd.PrintUsage()
-		os.Exit(2)
+	mustCheckRestartArgs(cmd, args)
+	if flagRestartCrashed {
+		restartCrashed(appname, args)
+		return
+	}
+	if flagRestartRolling {
+		rollingRestart(appname, args[0])
+		return
 	}
 
 	target := "all"
@@ -51,3 +100,166 @@ func runRestart(cmd *Command, args []string) {
 		log.Printf("Restarted %s dynos for %s.", target, appname)
 	}
 }
+
+func planRestart(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	mustCheckRestartArgs(cmd, args)
+	dynos, err := client.DynoList(appname, nil)
+	must(err)
+	dynos = filterDynos(dynos, args)
+	if flagRestartCrashed {
+		dynos = crashedDynos(dynos)
+	}
+	sort.Sort(DynosByName(dynos))
+
+	p := newPlan(appname)
+	for _, d := range dynos {
+		p.Change(d.Name, d.State, "starting")
+	}
+	switch {
+	case flagRestartCrashed || flagRestartRolling:
+		for _, d := range dynos {
+			p.Request("DELETE", "/apps/"+appname+"/dynos/"+d.Name, nil)
+		}
+	case len(args) == 1:
+		p.Request("DELETE", "/apps/"+appname+"/dynos/"+args[0], nil)
+	default:
+		p.Request("DELETE", "/apps/"+appname+"/dynos", nil)
+	}
+	return p
+}
+
+// mustCheckRestartArgs exits with the usage if the arguments and flags of hk
+// restart don't go together.
+func mustCheckRestartArgs(cmd *Command, args []string) {
+	switch {
+	case len(args) > 1,
+		flagRestartCrashed && flagRestartRolling,
+		flagRestartRolling && (len(args) != 1 || strings.Contains(args[0], ".") || flagRestartBatch < 1):
+		cmd.PrintUsage()
+		exit(2)
+	}
+}
+
+// restartCrashed restarts the app's crashed dynos, optionally only those of
+// the given types or names.
+func restartCrashed(appname string, names []string) {
+	dynos, err := client.DynoList(appname, nil)
+	must(err)
+	crashed := crashedDynos(filterDynos(dynos, names))
+	if len(crashed) == 0 {
+		log.Printf("No crashed dynos on %s.", appname)
+		return
+	}
+	for _, d := range crashed {
+		must(client.DynoRestart(appname, d.Name))
+		log.Printf("Restarted %s (crashed %s ago).", d.Name, strings.TrimSpace(prettyDuration{dynoAge(&d)}.String()))
+	}
+}
+
+// crashedDynos returns the dynos that are crashed, in order of name.
+func crashedDynos(dynos []heroku.Dyno) []heroku.Dyno {
+	var crashed []heroku.Dyno
+	for _, d := range dynos {
+		if d.State == "crashed" {
+			crashed = append(crashed, d)
+		}
+	}
+	sort.Sort(DynosByName(crashed))
+	return crashed
+}
+
+// rollingRestart restarts the dynos of a process type a batch at a time,
+// waiting for each batch to be up before going on to the next.
+func rollingRestart(appname, ptype string) {
+	dynos, err := client.DynoList(appname, nil)
+	must(err)
+	dynos = filterDynos(dynos, []string{ptype})
+	if len(dynos) == 0 {
+		printFatal("No %s dynos on %s.", ptype, appname)
+	}
+	sort.Sort(DynosByName(dynos))
+
+	batches := dynoBatches(dynos, flagRestartBatch)
+	for i, batch := range batches {
+		names := make([]string, len(batch))
+		for j, d := range batch {
+			names[j] = d.Name
+		}
+		fmt.Printf("Restarting %s...", strings.Join(names, ", "))
+		for _, d := range batch {
+			must(client.DynoRestart(appname, d.Name))
+		}
+		if err := waitForRestart(appname, batch); err != nil {
+			fmt.Println()
+			printFatal("Stopped the rolling restart of %s dynos on %s: %s.", ptype, appname, err)
+		}
+		fmt.Println(" up.")
+		if i < len(batches)-1 && flagRestartPause > 0 {
+			time.Sleep(flagRestartPause)
+		}
+	}
+
+	how := "one at a time"
+	if flagRestartBatch > 1 {
+		how = fmt.Sprintf("%d at a time", flagRestartBatch)
+	}
+	log.Printf("Restarted %s dynos on %s %s.", ptype, appname, how)
+}
+
+// dynoBatches splits dynos into batches of up to n.
+func dynoBatches(dynos []heroku.Dyno, n int) [][]heroku.Dyno {
+	var batches [][]heroku.Dyno
+	for len(dynos) > n {
+		batches = append(batches, dynos[:n])
+		dynos = dynos[n:]
+	}
+	if len(dynos) > 0 {
+		batches = append(batches, dynos)
+	}
+	return batches
+}
+
+// waitForRestart polls the restarted dynos until they're all up again. It
+// returns an error if one crashes or they aren't all up within
+// dynoRestartTimeout.
+func waitForRestart(appname string, batch []heroku.Dyno) error {
+	deadline := time.Now().Add(dynoRestartTimeout)
+	pending := append([]heroku.Dyno(nil), batch...)
+	for {
+		time.Sleep(dynoPollInterval)
+		var still []heroku.Dyno
+		for _, before := range pending {
+			d, err := client.DynoInfo(appname, before.Name)
+			if err != nil {
+				return err
+			}
+			up, err := dynoRestarted(&before, d)
+			if err != nil {
+				return err
+			}
+			if !up {
+				still = append(still, before)
+			}
+		}
+		if pending = still; len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s wasn't up after %s", pending[0].Name, dynoRestartTimeout)
+		}
+	}
+}
+
+// dynoRestarted reports whether a dyno has come back up since before was
+// fetched, ahead of its restart. A dyno that's still up but hasn't been
+// updated hasn't restarted yet.
+func dynoRestarted(before, now *heroku.Dyno) (bool, error) {
+	switch {
+	case now.State == "crashed":
+		return false, fmt.Errorf("%s crashed", now.Name)
+	case now.State != "up":
+		return false, nil
+	}
+	return now.UpdatedAt.After(before.UpdatedAt), nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+)
+
+func TestDynoBatches(t *testing.T) {
+	var dynos []heroku.Dyno
+	for _, name := range []string{"web.1", "web.2", "web.3", "web.4", "web.5"} {
+		dynos = append(dynos, newTestDyno(name, "up", 1))
+	}
+	tests := []struct {
+		n     int
+		sizes []int
+	}{
+		{1, []int{1, 1, 1, 1, 1}},
+		{2, []int{2, 2, 1}},
+		{5, []int{5}},
+		{10, []int{5}},
+	}
+	for _, test := range tests {
+		batches := dynoBatches(dynos, test.n)
+		if len(batches) != len(test.sizes) {
+			t.Errorf("dynoBatches(%d) = %d batches, want %d", test.n, len(batches), len(test.sizes))
+			continue
+		}
+		for i, b := range batches {
+			if len(b) != test.sizes[i] {
+				t.Errorf("dynoBatches(%d)[%d] has %d dynos, want %d", test.n, i, len(b), test.sizes[i])
+			}
+		}
+	}
+	if batches := dynoBatches(nil, 1); len(batches) != 0 {
+		t.Errorf("dynoBatches(nil) = %d batches, want 0", len(batches))
+	}
+}
+
+func TestDynoRestarted(t *testing.T) {
+	t0 := time.Date(2014, 6, 1, 12, 0, 0, 0, time.UTC)
+	before := newTestDyno("web.1", "up", 1)
+	before.UpdatedAt = t0
+
+	tests := []struct {
+		state   string
+		updated time.Time
+		up      bool
+		err     bool
+	}{
+		{"up", t0, false, false},
+		{"restarting", t0.Add(time.Second), false, false},
+		{"starting", t0.Add(time.Second), false, false},
+		{"up", t0.Add(time.Second), true, false},
+		{"crashed", t0.Add(time.Second), false, true},
+	}
+	for _, test := range tests {
+		now := newTestDyno("web.1", test.state, 1)
+		now.UpdatedAt = test.updated
+		up, err := dynoRestarted(&before, &now)
+		if up != test.up || (err != nil) != test.err {
+			t.Errorf("dynoRestarted(%s at %s) = %v, %v, want %v, error %v", test.state, test.updated, up, err, test.up, test.err)
+		}
+	}
+}
+
+func TestCrashedDynos(t *testing.T) {
+	dynos := []heroku.Dyno{
+		newTestDyno("worker.2", "crashed", 1),
+		newTestDyno("web.1", "up", 1),
+		newTestDyno("worker.1", "crashed", 1),
+		newTestDyno("worker.3", "starting", 1),
+	}
+	var names []string
+	for _, d := range crashedDynos(dynos) {
+		names = append(names, d.Name)
+	}
+	if want := []string{"worker.1", "worker.2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("crashedDynos = %v, want %v", names, want)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/tls"
 	"io"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
@@ -17,13 +19,14 @@ import (
 )
 
 var (
-	detachedRun bool
-	dynoSize    string
+	detachedRun      bool
+	dynoSize         string
+	flagNoExitStatus bool
 )
 
 var cmdRun = &Command{
 	Run:      runRun,
-	Usage:    "run [-s <size>] [-d] <command> [<argument>...]",
+	Usage:    "run [-s <size>] [-d] [--no-exit-status] <command> [<argument>...]",
 	NeedsApp: true,
 	Category: "dyno",
 	Short:    "run a process in a dyno",
@@ -32,10 +35,21 @@ Run a process on Heroku. Flags such as` + " `-a` " + `may be parsed out of
 the command unless the command is quoted or provided after a
 double-dash (--).
 
+When attached, hk exits with the exit status of the command, so
+scripts can tell whether it failed. To get it, hk appends to the
+command a line that prints the status, and strips that line from
+the output. Use --no-exit-status to run the command as it is.
+
+The dyno's terminal gets the size of hk's terminal when the command
+starts. The attach protocol can't change it afterwards, so after
+resizing hk's terminal, tell full-screen programs the new size with
+e.g. ` + "`stty cols 120 rows 40`" + ` in the dyno.
+
 Options:
 
-    -s <size>  set the size for this dyno (e.g. 2X)
-    -d         run in detached mode instead of attached to terminal
+    -s <size>         set the size for this dyno (e.g. 2X)
+    -d                run in detached mode instead of attached to terminal
+    --no-exit-status  don't exit with the command's exit status
 
 Examples:
 
@@ -61,12 +75,13 @@ Examples:
 func init() {
 	cmdRun.Flag.BoolVarP(&detachedRun, "detached", "d", false, "detached")
 	cmdRun.Flag.StringVarP(&dynoSize, "size", "s", "", "dyno size")
+	cmdRun.Flag.BoolVar(&flagNoExitStatus, "no-exit-status", false, "don't exit with the command's exit status")
 }
 
 func runRun(cmd *Command, args []string) {
 	if len(args) == 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 
@@ -92,20 +107,25 @@ func runRun(cmd *Command, args []string) {
 	if dynoSize != "" {
 		if !strings.HasSuffix(dynoSize, "X") {
 			cmd.PrintUsage()
-			os.Exit(2)
+			exit(2)
 		}
 		opts.Size = &dynoSize
 	}
 
 	command := strings.Join(args, " ")
-	dyno, err := client.DynoCreate(appname, command, &opts)
+	exitStatus := attached && !flagNoExitStatus
+	remoteCommand := command
+	if exitStatus {
+		remoteCommand = exitStatusCommand(command)
+	}
+	dyno, err := client.DynoCreate(appname, remoteCommand, &opts)
 	must(err)
 
 	if detachedRun {
 		log.Printf("Ran `%s` on %s as %s, detached.", dyno.Command, appname, dyno.Name)
 		return
 	}
-	log.Printf("Running `%s` on %s as %s:", dyno.Command, appname, dyno.Name)
+	log.Printf("Running `%s` on %s as %s:", command, appname, dyno.Name)
 
 	u, err := url.Parse(*dyno.AttachURL)
 	if err != nil {
@@ -135,17 +155,34 @@ func runRun(cmd *Command, args []string) {
 		}
 	}
 
+	stopc := make(chan os.Signal, 1)
 	if term.IsTerminal(os.Stdin) && term.IsTerminal(os.Stdout) {
 		err = term.MakeRaw(os.Stdin)
 		if err != nil {
 			printFatal(err.Error())
 		}
-		defer term.Restore(os.Stdin)
 
-		sig := make(chan os.Signal)
+		// Restore the terminal however hk exits, including through
+		// printFatal or exit, which skip deferred calls.
+		var once sync.Once
+		restore := func() { once.Do(func() { term.Restore(os.Stdin) }) }
+		defer restore()
+		prevExit := exit
+		exit = func(code int) {
+			restore()
+			prevExit(code)
+		}
+		defer func() { exit = prevExit }()
+
+		// SIGTERM and SIGHUP end the session from this goroutine below,
+		// as exit can't be called from another.
+		signal.Notify(stopc, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(stopc)
+
+		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, os.Signal(syscall.SIGQUIT), os.Interrupt)
+		defer signal.Stop(sig)
 		go func() {
-			defer term.Restore(os.Stdin)
 			for sg := range sig {
 				switch sg {
 				case os.Interrupt:
@@ -159,15 +196,119 @@ func runRun(cmd *Command, args []string) {
 		}()
 	}
 
-	errc := make(chan error)
-	cp := func(a io.Writer, b io.Reader) {
+	var out io.Writer = os.Stdout
+	var status *exitStatusWriter
+	if exitStatus {
+		status = &exitStatusWriter{w: os.Stdout}
+		out = status
+	}
+
+	outc, inc := make(chan error, 1), make(chan error, 1)
+	cp := func(a io.Writer, b io.Reader, errc chan error) {
 		_, err := io.Copy(a, b)
 		errc <- err
 	}
 
-	go cp(os.Stdout, br)
-	go cp(cn, os.Stdin)
-	if err = <-errc; err != nil {
+	go cp(out, br, outc)
+	go cp(cn, os.Stdin, inc)
+	for {
+		select {
+		case err = <-inc:
+			if err == nil && exitStatus {
+				// Keep reading until the command exits, so its status
+				// isn't lost. ^D lets it see the end of its input.
+				cn.Write([]byte{4})
+				inc = nil
+				continue
+			}
+		case err = <-outc:
+		case <-stopc:
+			exit(1)
+		}
+		break
+	}
+	if err != nil {
 		printFatal(err.Error())
 	}
+	if status == nil {
+		return
+	}
+	status.Flush()
+	if !status.found {
+		printFatal("Couldn't get the exit status of %s; it may have been stopped.", dyno.Name)
+	}
+	if status.status != 0 {
+		exit(status.status)
+	}
+}
+
+// exitStatusSentinel starts the line hk run appends to the output of a
+// command to get its exit status.
+const exitStatusSentinel = "\uFFFF hk-exit-status: "
+
+// exitStatusCommand returns command followed by a line that prints its
+// exit status after exitStatusSentinel. The line is separate so that a
+// command ending in a comment or in & still gets it.
+func exitStatusCommand(command string) string {
+	return command + "\necho \"" + exitStatusSentinel + "$?\""
+}
+
+// An exitStatusWriter copies the output of a command to w, taking out the
+// line with its exit status.
+type exitStatusWriter struct {
+	w      io.Writer
+	buf    []byte
+	status int
+	found  bool
+}
+
+func (sw *exitStatusWriter) Write(p []byte) (int, error) {
+	sw.buf = append(sw.buf, p...)
+	for {
+		i := bytes.Index(sw.buf, []byte(exitStatusSentinel))
+		if i < 0 {
+			// hold back what may be the start of the sentinel
+			n := len(sw.buf) - partialSuffix(sw.buf, exitStatusSentinel)
+			if _, err := sw.w.Write(sw.buf[:n]); err != nil {
+				return 0, err
+			}
+			sw.buf = sw.buf[n:]
+			return len(p), nil
+		}
+		if _, err := sw.w.Write(sw.buf[:i]); err != nil {
+			return 0, err
+		}
+		sw.buf = sw.buf[i:]
+		rest := sw.buf[len(exitStatusSentinel):]
+		j := bytes.IndexByte(rest, '\n')
+		if j < 0 {
+			return len(p), nil // the rest of the line is still to come
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(string(rest[:j]))); err == nil {
+			sw.status, sw.found = n, true
+		}
+		sw.buf = rest[j+1:]
+	}
+}
+
+// Flush writes any output held back.
+func (sw *exitStatusWriter) Flush() error {
+	_, err := sw.w.Write(sw.buf)
+	sw.buf = nil
+	return err
+}
+
+// partialSuffix returns the length of the longest suffix of b that's a
+// proper prefix of s.
+func partialSuffix(b []byte, s string) int {
+	n := len(s) - 1
+	if n > len(b) {
+		n = len(b)
+	}
+	for ; n > 0; n-- {
+		if bytes.HasSuffix(b, []byte(s[:n])) {
+			return n
+		}
+	}
+	return 0
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+)
+
+func TestExitStatusWriter(t *testing.T) {
+	tests := []struct {
+		writes []string
+		out    string
+		status int
+		found  bool
+	}{
+		{
+			[]string{"hello\r\n", exitStatusSentinel + "0\r\n"},
+			"hello\r\n", 0, true,
+		},
+		{
+			[]string{"migrating...\r\nfailed\r\n" + exitStatusSentinel + "3\r\n"},
+			"migrating...\r\nfailed\r\n", 3, true,
+		},
+		{
+			// the sentinel split across writes
+			[]string{"no newline", exitStatusSentinel[:4], exitStatusSentinel[4:] + "1", "27\r\n"},
+			"no newline", 127, true,
+		},
+		{
+			// the connection closed before the status
+			[]string{"partial\n", exitStatusSentinel[:5]},
+			"partial\n" + exitStatusSentinel[:5], 0, false,
+		},
+		{
+			[]string{"\xef\xbf plain bytes\n"},
+			"\xef\xbf plain bytes\n", 0, false,
+		},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		sw := &exitStatusWriter{w: &buf}
+		for _, s := range test.writes {
+			if n, err := sw.Write([]byte(s)); n != len(s) || err != nil {
+				t.Fatalf("%d: Write(%q) = %d, %v", i, s, n, err)
+			}
+		}
+		sw.Flush()
+		if buf.String() != test.out {
+			t.Errorf("%d: output = %q, want %q", i, buf.String(), test.out)
+		}
+		if sw.status != test.status || sw.found != test.found {
+			t.Errorf("%d: status = %d, %v, want %d, %v", i, sw.status, sw.found, test.status, test.found)
+		}
+	}
+}
+
+func TestExitStatusCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		out     string
+		status  int
+	}{
+		{"echo hello", "hello\n", 0},
+		{"echo hello; false", "hello\n", 1},
+		{"(exit 3) # a comment", "", 3},
+		{"sleep 0 &", "", 0},
+	}
+	for _, test := range tests {
+		out, err := exec.Command("sh", "-c", exitStatusCommand(test.command)).Output()
+		if err != nil {
+			t.Fatalf("%q: %s", test.command, err)
+		}
+		var buf bytes.Buffer
+		sw := &exitStatusWriter{w: &buf}
+		sw.Write(out)
+		sw.Flush()
+		if buf.String() != test.out || !sw.found || sw.status != test.status {
+			t.Errorf("%q: output %q, status %d (found %v), want %q, %d", test.command, buf.String(), sw.status, sw.found, test.out, test.status)
+		}
+	}
+}
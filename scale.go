@@ -3,7 +3,6 @@ package main
 import (
 	"errors"
 	"log"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,7 +12,8 @@ import (
 
 var cmdScale = &Command{
 	Run:      runScale,
-	Usage:    "scale <type>=[<qty>]:[<size>]...",
+	Plan:     planScale,
+	Usage:    "scale [--dry-run] <type>=[<qty>]:[<size>]...",
 	NeedsApp: true,
 	Category: "dyno",
 	Short:    "change dyno quantities and sizes",
@@ -22,6 +22,10 @@ Scale changes the quantity of dynos (horizontal scale) and/or the
 dyno size (vertical scale) for each process type. Note that
 changing dyno size will restart all dynos of that type.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Examples:
 
     $ hk scale web=2
@@ -32,15 +36,75 @@ Examples:
 
     $ hk scale web=PX worker=1X
     Scaled myapp to web=2:PX, worker=5:1X.
+
+    $ hk scale --dry-run web=3
+    Changes to myapp:
+    - web=2:PX
+    + web=3:PX
+
+    Requests:
+      PATCH /apps/myapp/formation {"updates":[{"process":"web","quantity":3}]}
 `,
 }
 
 // takes args of the form "web=1", "worker=3X", web=4:2X etc
 func runScale(cmd *Command, args []string) {
 	appname := mustApp()
+	todo, types := mustParseScaleArgs(cmd, args)
+
+	formations, err := client.FormationBatchUpdate(appname, todo)
+	must(err)
+
+	sortedFormations := formationsByType(formations)
+	sort.Sort(sortedFormations)
+	results := make([]string, len(types))
+	rindex := 0
+	for _, f := range sortedFormations {
+		if _, exists := types[f.Type]; exists {
+			results[rindex] = f.Type + "=" + strconv.Itoa(f.Quantity) + ":" + f.Size
+			rindex += 1
+		}
+	}
+	log.Printf("Scaled %s to %s.", appname, strings.Join(results, ", "))
+}
+
+func planScale(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	todo, _ := mustParseScaleArgs(cmd, args)
+
+	formations, err := client.FormationList(appname, nil)
+	must(err)
+	current := make(map[string]heroku.Formation)
+	for _, f := range formations {
+		current[f.Type] = f
+	}
+
+	p := newPlan(appname)
+	for _, opt := range todo {
+		var before, after string
+		f, exists := current[opt.Process]
+		if exists {
+			before = strconv.Itoa(f.Quantity) + ":" + f.Size
+		}
+		if opt.Quantity != nil {
+			f.Quantity = *opt.Quantity
+		}
+		if opt.Size != nil {
+			f.Size = *opt.Size
+		}
+		after = strconv.Itoa(f.Quantity) + ":" + f.Size
+		p.Change(opt.Process, before, after)
+	}
+	p.Request("PATCH", "/apps/"+appname+"/formation", map[string]interface{}{"updates": todo})
+	return p
+}
+
+// mustParseScaleArgs parses the arguments of hk scale into formation
+// updates, and returns the set of process types being scaled.
+func mustParseScaleArgs(cmd *Command, args []string) ([]heroku.FormationBatchUpdateOpts, map[string]bool) {
 	if len(args) == 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	todo := make([]heroku.FormationBatchUpdateOpts, len(args))
 	types := make(map[string]bool)
@@ -48,13 +112,13 @@ func runScale(cmd *Command, args []string) {
 		pstype, qty, size, err := parseScaleArg(arg)
 		if err != nil {
 			cmd.PrintUsage()
-			os.Exit(2)
+			exit(2)
 		}
 		if _, exists := types[pstype]; exists {
 			// can only specify each process type once
 			printError("process type '%s' specified more than once", pstype)
 			cmd.PrintUsage()
-			os.Exit(2)
+			exit(2)
 		}
 		types[pstype] = true
 
@@ -67,21 +131,7 @@ func runScale(cmd *Command, args []string) {
 		}
 		todo[i] = opt
 	}
-
-	formations, err := client.FormationBatchUpdate(appname, todo)
-	must(err)
-
-	sortedFormations := formationsByType(formations)
-	sort.Sort(sortedFormations)
-	results := make([]string, len(types))
-	rindex := 0
-	for _, f := range sortedFormations {
-		if _, exists := types[f.Type]; exists {
-			results[rindex] = f.Type + "=" + strconv.Itoa(f.Quantity) + ":" + f.Size
-			rindex += 1
-		}
-	}
-	log.Printf("Scaled %s to %s.", appname, strings.Join(results, ", "))
+	return todo, types
 }
 
 var errInvalidScaleArg = errors.New("invalid argument")
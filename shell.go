@@ -0,0 +1,596 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/heroku/hk/hkclient"
+	"github.com/heroku/hk/term"
+)
+
+var cmdShell = &Command{
+	Usage:    "shell [<app>]",
+	Category: "hk",
+	Short:    "run hk commands interactively" + extra,
+	Long: `
+Shell reads hk commands from the terminal and runs them, reusing
+the same API connection for each command. Type commands as you
+would after "hk", e.g. "apps" or "scale web=2".
+
+In addition to hk's commands, the shell understands:
+
+    use <app>  run subsequent commands against app
+    use        show the current app
+    exit       leave the shell (or press Ctrl-D)
+
+Press Tab to complete command names, app names after "use" or
+-a, and process types after commands such as scale and restart.
+Use the up and down arrows to recall previous commands, which
+are saved in ~/.hk/shell_history.
+
+Example:
+
+    $ hk shell
+    hk> use myapp
+    hk myapp> scale web=2
+    Scaled myapp to web=2:1X.
+    hk myapp> exit
+`,
+}
+
+func init() {
+	cmdShell.Run = runShell
+}
+
+// shellExit is the panic value used to end a command run by hk shell when
+// it calls exit.
+type shellExit int
+
+const shellHistoryMax = 1000
+
+func runShell(cmd *Command, args []string) {
+	if len(args) > 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	if len(args) == 1 {
+		os.Setenv("HKAPP", args[0])
+	}
+
+	exit = func(code int) { panic(shellExit(code)) }
+	defer func() { exit = os.Exit }()
+
+	// Ctrl-C interrupts a command such as hk run, but not the shell
+	// itself. Commands that run until interrupted, like hk log -t, watch
+	// for os.Interrupt themselves, which works the same in the shell.
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+	go func() {
+		for _ = range sigc {
+		}
+	}()
+
+	sh := &shell{history: loadShellHistory()}
+	for {
+		line, err := sh.readLine()
+		if err == io.EOF {
+			fmt.Println()
+			return
+		} else if err != nil {
+			// outside of a command, so nothing would recover a shellExit
+			exit = os.Exit
+			printFatal(err.Error())
+		}
+		words, err := shellSplit(line)
+		if err != nil {
+			printError(err.Error())
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		sh.addHistory(line)
+		if words[0] == "hk" {
+			words = words[1:]
+			if len(words) == 0 {
+				continue
+			}
+		}
+		if !sh.run(words) {
+			return
+		}
+	}
+}
+
+type shell struct {
+	history []string
+	apps    []string            // cached app names, for completion
+	ptypes  map[string][]string // cached process types by app
+}
+
+// run runs the command line words, and returns false if the shell should
+// exit.
+func (sh *shell) run(words []string) bool {
+	switch words[0] {
+	case "exit", "quit":
+		return false
+	case "use":
+		switch len(words) {
+		case 1:
+			if a, _ := app(); a != "" {
+				fmt.Println(a)
+			} else {
+				printError("no app selected")
+			}
+		case 2:
+			os.Setenv("HKAPP", words[1])
+		default:
+			printError("Usage: use <app>")
+		}
+		return true
+	case cmdShell.Name():
+		printError("already in hk shell")
+		return true
+	}
+
+	if cmd := findCommand(words[0]); cmd != nil {
+		runShellCommand(cmd, words[1:])
+		return true
+	}
+	if path := lookupPlugin(words[0]); path != "" {
+		if err := runPlugin(path, words); err != nil {
+			printError(err.Error())
+		}
+		return true
+	}
+	printError("Unknown command: %s", words[0])
+	if g := suggest(words[0]); len(g) > 0 {
+		fmt.Fprintf(os.Stderr, "Possible alternatives: %v\n", strings.Join(g, " "))
+	}
+	return true
+}
+
+// runShellCommand runs cmd, recovering if it exits, and returns its exit
+// status.
+func runShellCommand(cmd *Command, args []string) (status int) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			code, ok := rec.(shellExit)
+			if !ok {
+				panic(rec)
+			}
+			status = int(code)
+		}
+	}()
+	dispatchCommand(cmd, args)
+	return 0
+}
+
+// runPlugin runs the plugin at path as a child process, rather than
+// replacing hk with it as execPlugin does.
+func runPlugin(path string, args []string) error {
+	c := exec.Command(path, args[1:]...)
+	c.Env = pluginEnv()
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := c.Run()
+	if _, ok := err.(*exec.ExitError); ok {
+		return nil // the plugin has reported the error
+	}
+	return err
+}
+
+func (sh *shell) prompt() string {
+	if a, _ := app(); a != "" {
+		return "hk " + a + "> "
+	}
+	return "hk> "
+}
+
+// readLine reads a command line, with line editing if stdin is a terminal.
+func (sh *shell) readLine() (string, error) {
+	if !term.IsTerminal(os.Stdin) {
+		return readPlainLine(os.Stdin)
+	}
+	if err := term.MakeRaw(os.Stdin); err != nil {
+		return "", err
+	}
+	defer term.Restore(os.Stdin)
+	ed := &lineEditor{
+		in:       os.Stdin,
+		out:      os.Stdout,
+		prompt:   sh.prompt(),
+		history:  sh.history,
+		complete: sh.complete,
+	}
+	return ed.readLine()
+}
+
+// readPlainLine reads a line from r one byte at a time, so that it doesn't
+// consume input meant for the commands it runs.
+func readPlainLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n == 1 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+	}
+}
+
+// history
+
+func shellHistoryPath() string {
+	return filepath.Join(hkclient.HomePath(), ".hk", "shell_history")
+}
+
+func loadShellHistory() []string {
+	f, err := os.Open(shellHistoryPath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var history []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := s.Text(); line != "" {
+			history = append(history, line)
+		}
+	}
+	if len(history) > shellHistoryMax {
+		history = history[len(history)-shellHistoryMax:]
+	}
+	return history
+}
+
+func (sh *shell) addHistory(line string) {
+	if n := len(sh.history); n > 0 && sh.history[n-1] == line {
+		return
+	}
+	sh.history = append(sh.history, line)
+	path := shellHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// completion
+
+// scaleCommands take process types followed by "=".
+var scaleCommands = map[string]bool{"scale": true}
+
+// psCommands take process types or dyno names as arguments.
+var psCommands = map[string]bool{"restart": true, "dynos": true}
+
+// complete returns the possible completions of the last word of line.
+func (sh *shell) complete(line string) []string {
+	words := strings.Fields(line)
+	if len(words) > 0 && words[0] == "hk" {
+		words = words[1:]
+	}
+	if strings.HasSuffix(line, " ") || len(words) == 0 {
+		words = append(words, "")
+	}
+	word := words[len(words)-1]
+	if len(words) == 1 {
+		return matchPrefix(shellCommandNames(), word)
+	}
+
+	prev := words[len(words)-2]
+	switch {
+	case words[0] == "use" && len(words) == 2, prev == "-a", prev == "--app":
+		return matchPrefix(sh.appNames(), word)
+	case words[0] == cmdHelp.Name() && len(words) == 2:
+		return matchPrefix(shellCommandNames(), word)
+	case scaleCommands[words[0]]:
+		var types []string
+		for _, t := range sh.processTypes() {
+			types = append(types, t+"=")
+		}
+		return matchPrefix(types, word)
+	case psCommands[words[0]]:
+		return matchPrefix(sh.processTypes(), word)
+	}
+	return nil
+}
+
+func shellCommandNames() []string {
+	names := []string{"exit", "use"}
+	for _, cmd := range commands {
+		if cmd.Runnable() && cmd != cmdShell {
+			names = append(names, cmd.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (sh *shell) appNames() []string {
+	if sh.apps == nil {
+		apps, err := getAppList(orgName())
+		if err != nil {
+			return nil
+		}
+		sh.apps = make([]string, 0, len(apps))
+		for _, a := range apps {
+			sh.apps = append(sh.apps, a.Name)
+		}
+		sort.Strings(sh.apps)
+	}
+	return sh.apps
+}
+
+func (sh *shell) processTypes() []string {
+	appname, _ := app()
+	if appname == "" {
+		return nil
+	}
+	if types, ok := sh.ptypes[appname]; ok {
+		return types
+	}
+	formations, err := client.FormationList(appname, nil)
+	if err != nil {
+		return nil
+	}
+	types := make([]string, 0, len(formations))
+	for _, f := range formations {
+		types = append(types, f.Type)
+	}
+	sort.Strings(types)
+	if sh.ptypes == nil {
+		sh.ptypes = make(map[string][]string)
+	}
+	sh.ptypes[appname] = types
+	return types
+}
+
+func matchPrefix(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+func commonPrefix(a []string) string {
+	if len(a) == 0 {
+		return ""
+	}
+	prefix := a[0]
+	for _, s := range a[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// shellSplit splits line into words like a Unix shell, honoring single
+// quotes, double quotes, and backslash escapes.
+func shellSplit(line string) ([]string, error) {
+	var (
+		words   []string
+		word    []rune
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			word = append(word, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word = append(word, r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+		default:
+			word = append(word, r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inWord {
+		words = append(words, string(word))
+	}
+	return words, nil
+}
+
+// A lineEditor reads a line from a terminal in raw mode, with basic editing,
+// history, and tab completion. The cursor is always at the end of the line.
+type lineEditor struct {
+	in       io.Reader
+	out      io.Writer
+	prompt   string
+	history  []string
+	complete func(line string) []string
+
+	line []byte
+}
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyCtrlL     = 12
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+func (ed *lineEditor) readLine() (string, error) {
+	ed.redraw()
+	hpos := len(ed.history) // position in history; len means the new line
+	var saved []byte        // the new line, while browsing history
+	for {
+		c, err := ed.readByte()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '\r', '\n':
+			fmt.Fprint(ed.out, "\r\n")
+			return string(ed.line), nil
+		case keyCtrlD:
+			if len(ed.line) == 0 {
+				return "", io.EOF
+			}
+		case keyCtrlC:
+			fmt.Fprint(ed.out, "^C\r\n")
+			ed.line = ed.line[:0]
+			ed.redraw()
+		case keyBackspace, keyDelete:
+			if len(ed.line) > 0 {
+				_, size := utf8.DecodeLastRune(ed.line)
+				ed.line = ed.line[:len(ed.line)-size]
+				ed.redraw()
+			}
+		case keyCtrlU:
+			ed.line = ed.line[:0]
+			ed.redraw()
+		case keyCtrlW:
+			s := strings.TrimRight(string(ed.line), " ")
+			ed.line = []byte(s[:strings.LastIndex(s, " ")+1])
+			ed.redraw()
+		case keyCtrlL:
+			fmt.Fprint(ed.out, "\033[H\033[2J")
+			ed.redraw()
+		case keyTab:
+			ed.completeLine()
+		case keyEscape:
+			seq, err := ed.readEscape()
+			if err != nil {
+				return "", err
+			}
+			switch seq {
+			case "[A", "OA": // up
+				if hpos > 0 {
+					if hpos == len(ed.history) {
+						saved = append([]byte(nil), ed.line...)
+					}
+					hpos--
+					ed.line = []byte(ed.history[hpos])
+					ed.redraw()
+				}
+			case "[B", "OB": // down
+				if hpos < len(ed.history) {
+					hpos++
+					if hpos == len(ed.history) {
+						ed.line = saved
+					} else {
+						ed.line = []byte(ed.history[hpos])
+					}
+					ed.redraw()
+				}
+			}
+		default:
+			if c >= ' ' {
+				ed.line = append(ed.line, c)
+				ed.out.Write([]byte{c})
+			}
+		}
+	}
+}
+
+func (ed *lineEditor) readByte() (byte, error) {
+	b := make([]byte, 1)
+	for {
+		n, err := ed.in.Read(b)
+		if n == 1 {
+			return b[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence, e.g. "[A" for the up
+// arrow.
+func (ed *lineEditor) readEscape() (string, error) {
+	var seq []byte
+	for {
+		c, err := ed.readByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, c)
+		if len(seq) > 1 && c >= 0x40 && c <= 0x7e {
+			return string(seq), nil
+		}
+		if len(seq) == 1 && c != '[' && c != 'O' {
+			return string(seq), nil
+		}
+	}
+}
+
+func (ed *lineEditor) redraw() {
+	fmt.Fprintf(ed.out, "\r\033[K%s%s", ed.prompt, ed.line)
+}
+
+func (ed *lineEditor) completeLine() {
+	if ed.complete == nil {
+		return
+	}
+	line := string(ed.line)
+	matches := ed.complete(line)
+	if len(matches) == 0 {
+		return
+	}
+	word := line[strings.LastIndex(line, " ")+1:]
+	prefix := commonPrefix(matches)
+	if len(matches) == 1 && !strings.HasSuffix(prefix, "=") {
+		prefix += " "
+	}
+	if len(prefix) > len(word) {
+		ed.line = append(ed.line, prefix[len(word):]...)
+		ed.redraw()
+		return
+	}
+	if len(matches) > 1 {
+		fmt.Fprintf(ed.out, "\r\n%s\r\n", strings.Join(matches, "  "))
+		ed.redraw()
+	}
+}
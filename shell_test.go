@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShellSplit(t *testing.T) {
+	var tests = []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  apps  ", []string{"apps"}},
+		{"set A=1 B=2", []string{"set", "A=1", "B=2"}},
+		{`set MSG="hello world"`, []string{"set", "MSG=hello world"}},
+		{`set MSG='it''s'`, []string{"set", "MSG=its"}},
+		{`set MSG="say \"hi\""`, []string{"set", `MSG=say "hi"`}},
+		{`run echo a\ b`, []string{"run", "echo", "a b"}},
+		{`set EMPTY=""`, []string{"set", "EMPTY="}},
+	}
+	for _, tt := range tests {
+		got, err := shellSplit(tt.line)
+		if err != nil {
+			t.Errorf("shellSplit(%q): %s", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("shellSplit(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	if _, err := shellSplit(`set MSG="oops`); err == nil {
+		t.Errorf("expected error for unterminated quote")
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	sh := &shell{
+		apps:   []string{"myapp", "myapp-eu", "other"},
+		ptypes: map[string][]string{"myapp": {"web", "worker"}},
+	}
+	flagApp = "myapp"
+	defer func() { flagApp = "" }()
+
+	var tests = []struct {
+		line string
+		want []string
+	}{
+		{"scal", []string{"scale"}},
+		{"use my", []string{"myapp", "myapp-eu"}},
+		{"info -a o", []string{"other"}},
+		{"scale w", []string{"web=", "worker="}},
+		{"restart wo", []string{"worker"}},
+		{"env ", nil},
+	}
+	for _, tt := range tests {
+		if got := sh.complete(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("complete(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
-	"os"
 	"strings"
 	"time"
 
@@ -22,7 +21,7 @@ var cmdSSL = &Command{
 func runSSL(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	endpoints, err := client.SSLEndpointList(mustApp(), nil)
 	must(err)
@@ -72,7 +71,7 @@ func init() {
 func runSSLCertAdd(cmd *Command, args []string) {
 	if len(args) != 2 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 
@@ -113,7 +112,7 @@ func runSSLCertAdd(cmd *Command, args []string) {
 
 var cmdSSLDestroy = &Command{
 	Run:      runSSLDestroy,
-	Usage:    "ssl-destroy",
+	Usage:    "ssl-destroy [--confirm <app>]",
 	NeedsApp: true,
 	Category: "ssl",
 	Short:    "destroy ssl endpoint",
@@ -121,7 +120,12 @@ var cmdSSLDestroy = &Command{
 Removes the SSL endpoints from an app along with all SSL
 certificates. If your app's DNS is still configured to point at
 the SSL endpoint, this may take your app offline. The command
-will prompt for confirmation, or accept confirmation via stdin.
+will prompt for confirmation, or accept confirmation via stdin or
+the --confirm flag.
+
+Options:
+
+    --confirm <app>  confirm destroying the endpoint without a prompt
 
 Examples:
 
@@ -132,13 +136,20 @@ Examples:
 
     $ echo myapp | hk ssl-destroy
     Destroyed SSL endpoint on myapp.
+
+    $ hk ssl-destroy --confirm myapp
+    Destroyed SSL endpoint on myapp.
 `,
 }
 
+func init() {
+	cmdSSLDestroy.Flag.StringVar(&flagConfirm, "confirm", "", "app name to confirm")
+}
+
 func runSSLDestroy(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 
@@ -150,7 +161,7 @@ func runSSLDestroy(cmd *Command, args []string) {
 	}
 
 	warning := "This will destroy the SSL endpoint on %s. Please type %q to continue:"
-	mustConfirm(fmt.Sprintf(warning, appname, appname), appname)
+	mustConfirm(appname, fmt.Sprintf(warning, appname, appname), appname)
 
 	err = client.SSLEndpointDelete(appname, endpoints[0].Id)
 	must(err)
@@ -176,7 +187,7 @@ Examples:
 func runSSLCertRollback(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 
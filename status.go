@@ -47,7 +47,7 @@ type statusIssue struct {
 func runStatus(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	herokuStatusHost := "status.heroku.com"
 	if e := os.Getenv("HEROKU_STATUS_HOST"); e != "" {
@@ -5,8 +5,10 @@ package term
 import (
 	"os"
 	"os/exec"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
 func IsANSI(f *os.File) bool {
@@ -44,6 +46,12 @@ func Lines() (int, error) {
 	return strconv.Atoi(cols)
 }
 
+// NotifyResize causes SIGWINCH, which is sent when the size of the
+// terminal changes, to be relayed to c. Use signal.Stop to stop relaying.
+func NotifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
 // helpers
 
 func stty(f *os.File, args ...string) *exec.Cmd {
@@ -36,3 +36,7 @@ func Cols() (int, error) {
 func Lines() (int, error) {
 	return 24, nil
 }
+
+// NotifyResize is a no-op on Windows.
+func NotifyResize(c chan<- os.Signal) {
+}
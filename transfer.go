@@ -1,18 +1,16 @@
 package main
 
 import (
-	"io"
 	"log"
-	"os"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
 )
 
 var cmdTransfer = &Command{
 	Run:      runTransfer,
-	Usage:    "transfer <email or org name>",
+	Plan:     planTransfer,
+	Usage:    "transfer [--dry-run] <email or org name>",
 	NeedsApp: true,
 	Category: "app",
 	Short:    "transfer app ownership to a collaborator or an org" + extra,
@@ -20,6 +18,10 @@ var cmdTransfer = &Command{
 Transfer an app's ownership to a collaborator or a Heroku
 organization.
 
+Options:
+
+    --dry-run  show the changes without making them
+
 Examples:
 
     $ hk transfer user@test.com
@@ -34,14 +36,11 @@ func runTransfer(cmd *Command, args []string) {
 	appname := mustApp()
 	if len(args) != 1 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	recipient := args[0]
 
-	// if this app has no org AND it's being transferred to another user (email)
-	// then we use the regular app transfer endpoint, otherwise use the org
-	// endpoint.
-	if !isAppInOrg(mustGetOrgApp(appname)) && strings.Contains(recipient, "@") {
+	if !useOrgTransfer(mustGetOrgApp(appname), recipient) {
 		xfer, err := client.AppTransferCreate(appname, recipient)
 		must(err)
 		log.Printf("Requested transfer of %s to %s.", xfer.App.Name, xfer.Recipient.Email)
@@ -52,31 +51,69 @@ func runTransfer(cmd *Command, args []string) {
 	}
 }
 
+func planTransfer(cmd *Command, args []string) *plan {
+	appname := mustApp()
+	if len(args) != 1 {
+		cmd.PrintUsage()
+		exit(2)
+	}
+	recipient := args[0]
+	app := mustGetOrgApp(appname)
+
+	p := newPlan(appname)
+	var owner string
+	switch {
+	case isAppInOrg(app):
+		owner = app.Organization.Name
+	case app.Owner != nil:
+		owner = app.Owner.Email
+	}
+	p.Change("owner", owner, recipient)
+	if !useOrgTransfer(app, recipient) {
+		p.Request("POST", "/account/app-transfers", map[string]string{
+			"app":       appname,
+			"recipient": recipient,
+		})
+	} else {
+		p.Request("PATCH", "/organizations/apps/"+appname, map[string]string{"owner": recipient})
+	}
+	return p
+}
+
+// useOrgTransfer reports whether app must be transferred to recipient with
+// the org endpoint. If the app has no org AND it's being transferred to
+// another user (email) then we use the regular app transfer endpoint,
+// otherwise use the org endpoint.
+func useOrgTransfer(app *heroku.OrganizationApp, recipient string) bool {
+	return isAppInOrg(app) || !strings.Contains(recipient, "@")
+}
+
 var cmdTransfers = &Command{
 	Run:      runTransfers,
 	Usage:    "transfers",
 	NeedsApp: true,
 	Category: "app",
+	Raw:      true,
 	Short:    "list existing app transfer requests" + extra,
 }
 
 func runTransfers(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	transfers, err := client.AppTransferList(nil)
 	must(err)
 
-	w := tabwriter.NewWriter(os.Stdout, 1, 2, 2, ' ', 0)
+	w := newListWriter()
 	defer w.Flush()
 	for i := range transfers {
 		listTransfer(w, transfers[i])
 	}
 }
 
-func listTransfer(w io.Writer, t heroku.AppTransfer) {
-	listRec(w,
+func listTransfer(w *listWriter, t heroku.AppTransfer) {
+	w.Rec(t,
 		t.App.Name,
 		abbrev(t.Owner.Email, 10),
 		abbrev(t.Recipient.Email, 10),
@@ -96,7 +133,7 @@ var cmdTransferAccept = &Command{
 func runTransferAccept(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	xfer, err := client.AppTransferUpdate(mustApp(), "accepted")
 	must(err)
@@ -114,7 +151,7 @@ var cmdTransferDecline = &Command{
 func runTransferDecline(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	xfer, err := client.AppTransferUpdate(mustApp(), "declined")
 	must(err)
@@ -132,7 +169,7 @@ var cmdTransferCancel = &Command{
 func runTransferCancel(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	appname := mustApp()
 	must(client.AppTransferDelete(appname))
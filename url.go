@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"os"
 )
 
 var cmdURL = &Command{
@@ -17,7 +16,7 @@ var cmdURL = &Command{
 func runURL(cmd *Command, args []string) {
 	if len(args) != 0 {
 		cmd.PrintUsage()
-		os.Exit(2)
+		exit(2)
 	}
 	app, err := client.AppInfo(mustApp())
 	must(err)
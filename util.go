@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"net/url"
 	"os"
@@ -13,13 +12,20 @@ import (
 	"strings"
 	"time"
 
-	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/heroku-go"
+	"github.com/heroku/hk/Godeps/_workspace/src/github.com/bgentry/speakeasy"
 	"github.com/heroku/hk/Godeps/_workspace/src/github.com/mgutz/ansi"
 	"github.com/heroku/hk/hkclient"
 	"github.com/heroku/hk/term"
 )
 
-var nrc *hkclient.NetRc
+var (
+	nrc       *hkclient.NetRc
+	credStore hkclient.CredStore // used instead of nrc if configured
+
+	// credsPassphrase is the passphrase entered to unlock credStore, which hk
+	// foreach passes on to the commands it runs.
+	credsPassphrase string
+)
 
 func hkHome() string {
 	return filepath.Join(hkclient.HomePath(), ".hk")
@@ -47,18 +53,52 @@ func loadNetrc() {
 	}
 }
 
-func getCreds(u string) (user, pass string) {
-	loadNetrc()
-	if nrc == nil {
-		return "", ""
+// credStoreKind returns the kind of credential store selected with the
+// HKCREDSTORE env var or the hk.credential-store git config setting.
+func credStoreKind() string {
+	if s := os.Getenv("HKCREDSTORE"); s != "" {
+		return s
+	}
+	return gitConfig("hk.credential-store")
+}
+
+// loadCreds returns the selected credential store, which is netrc unless
+// another is configured.
+func loadCreds() hkclient.CredStore {
+	kind := credStoreKind()
+	if kind == "" || kind == hkclient.NetRcStore {
+		loadNetrc()
+		return nrc
+	}
+	if credStore == nil {
+		s, err := hkclient.NewCredStore(kind, readPassphrase)
+		if err != nil {
+			printFatal(err.Error())
+		}
+		credStore = s
+	}
+	return credStore
+}
+
+func readPassphrase() (string, error) {
+	if s := os.Getenv("HKPASSPHRASE"); s != "" {
+		return s, nil
+	}
+	mustBeInteractive("the credentials passphrase", "Set HKPASSPHRASE.")
+	s, err := speakeasy.Ask("Enter credentials passphrase: ")
+	if err == nil {
+		credsPassphrase = s
 	}
+	return s, err
+}
 
+func getCreds(u string) (user, pass string) {
 	apiURL, err := url.Parse(u)
 	if err != nil {
 		printFatal("invalid API URL: %s", err)
 	}
 
-	user, pass, err = nrc.GetCreds(apiURL)
+	user, pass, err = hkclient.ProfileCreds(loadCreds(), apiURL, profile)
 	if err != nil {
 		printError(err.Error())
 	}
@@ -67,30 +107,45 @@ func getCreds(u string) (user, pass string) {
 }
 
 func saveCreds(host, user, pass string) error {
-	loadNetrc()
-	m := nrc.FindMachine(host)
-	if m == nil || m.IsDefault() {
-		m = nrc.NewMachine(host, user, pass, "")
-	}
-	m.UpdateLogin(user)
-	m.UpdatePassword(pass)
-
-	body, err := nrc.MarshalText()
-	if err != nil {
-		return err
-	}
-	return ioutil.WriteFile(netrcPath(), body, 0600)
+	return loadCreds().SaveCreds(host, user, pass)
 }
 
 func removeCreds(host string) error {
-	loadNetrc()
-	nrc.RemoveMachine(host)
+	return loadCreds().RemoveCreds(host)
+}
 
-	body, err := nrc.MarshalText()
-	if err != nil {
-		return err
+// exit terminates hk with the given status. hk shell replaces it, so that
+// a failing command ends only that command and not the shell. It must only
+// be called from the goroutine running the command, since the shell can't
+// recover from it on any other.
+var exit = os.Exit
+
+// flagLimit is set by the --limit flag of listing commands.
+var flagLimit int
+
+// flagApps is set by the --apps flag of commands that work on several apps
+// at once, as a comma-separated list.
+var flagApps string
+
+// appsFlag returns the apps given with --apps, or nil if none were.
+func appsFlag() []string {
+	var apps []string
+	for _, a := range strings.Split(flagApps, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			apps = append(apps, a)
+		}
+	}
+	return apps
+}
+
+// limit returns how many of n results to list: all of them, or at most
+// flagLimit if it's set. Results are limited after they're sorted and
+// filtered, so that --limit shows the first of those that would be listed.
+func limit(n int) int {
+	if flagLimit > 0 && flagLimit < n {
+		return flagLimit
 	}
-	return ioutil.WriteFile(netrcPath(), body, 0600)
+	return n
 }
 
 // exists returns whether the given file or directory exists or not
@@ -107,16 +162,7 @@ func fileExists(path string) (bool, error) {
 
 func must(err error) {
 	if err != nil {
-		if herror, ok := err.(heroku.Error); ok {
-			switch herror.Id {
-			case "two_factor":
-				printError(err.Error() + " Authorize with `hk authorize`.")
-				os.Exit(79)
-			case "unauthorized":
-				printFatal(err.Error() + " Log in with `hk login`.")
-			}
-		}
-		printFatal(err.Error())
+		exitWithError(err)
 	}
 }
 
@@ -125,14 +171,58 @@ func printError(message string, args ...interface{}) {
 }
 
 func printFatal(message string, args ...interface{}) {
-	log.Fatal(colorizeMessage("red", "error:", message, args...))
+	if errorsJSON() {
+		if len(args) > 0 {
+			message = fmt.Sprintf(message, args...)
+		}
+		printErrorJSON(&errorInfo{Message: message, ExitCode: exitError})
+		exit(exitError)
+	}
+	log.Println(colorizeMessage("red", "error:", message, args...))
+	exit(1)
 }
 
 func printWarning(message string, args ...interface{}) {
 	log.Println(colorizeMessage("yellow", "warning:", message, args...))
 }
 
-func mustConfirm(warning, desired string) {
+// flagConfirm is set by the --confirm flag of destructive commands.
+var flagConfirm string
+
+// nonInteractive reports whether HK_NONINTERACTIVE is set, in which case hk
+// must never wait for input from the user.
+func nonInteractive() bool {
+	return os.Getenv("HK_NONINTERACTIVE") != ""
+}
+
+// mustBeInteractive exits if hk is running non-interactively, instead of
+// prompting for what. hint says how to provide it another way.
+func mustBeInteractive(what, hint string) {
+	if !nonInteractive() {
+		return
+	}
+	msg := fmt.Sprintf("hk needs %s, but HK_NONINTERACTIVE is set.", what)
+	if hint != "" {
+		msg += " " + hint
+	}
+	if errorsJSON() {
+		printErrorJSON(&errorInfo{Message: msg, ExitCode: exitNonInteractive})
+	} else {
+		printError(msg)
+	}
+	exit(exitNonInteractive)
+}
+
+// mustConfirm asks the user to type desired to confirm a destructive action
+// on appname, unless the action was confirmed with --confirm appname.
+func mustConfirm(appname, warning, desired string) {
+	if flagConfirm != "" {
+		if flagConfirm != appname {
+			printFatal("Confirmation did not match %q.", appname)
+		}
+		return
+	}
+	mustBeInteractive("confirmation", "Use --confirm "+appname+".")
 	if term.IsTerminal(os.Stdin) {
 		printWarning(warning)
 		fmt.Printf("> ")
@@ -147,6 +237,20 @@ func mustConfirm(warning, desired string) {
 	}
 }
 
+// defaultProductionApps are the patterns that match the names of production
+// apps, unless the hk.production-apps git config setting is set.
+const defaultProductionApps = "*-prod,*-production"
+
+// isProductionApp reports whether appname is a production app, whose
+// changes must be confirmed.
+func isProductionApp(appname string) bool {
+	patterns := gitConfig("hk.production-apps")
+	if patterns == "" {
+		patterns = defaultProductionApps
+	}
+	return len(matchAppNames([]string{appname}, strings.Split(patterns, ","))) > 0
+}
+
 func colorizeMessage(color, prefix, message string, args ...interface{}) string {
 	prefResult := ""
 	if prefix != "" {
@@ -235,7 +339,7 @@ func openURL(url string) error {
 	default:
 		if _, err := exec.LookPath("xdg-open"); err != nil {
 			log.Println("xdg-open is required to open web pages on " + runtime.GOOS)
-			os.Exit(2)
+			exit(2)
 		}
 		command = "xdg-open"
 		args = []string{command, url}
@@ -248,7 +352,7 @@ func runCommand(command string, args, env []string) error {
 		p, err := exec.LookPath(command)
 		if err != nil {
 			log.Printf("Error finding path to %q: %s\n", command, err)
-			os.Exit(2)
+			exit(2)
 		}
 		command = p
 	}
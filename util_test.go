@@ -86,3 +86,38 @@ func TestLoadNetrc(t *testing.T) {
 
 	cleanupNetrc()
 }
+
+func TestIsProductionApp(t *testing.T) {
+	if gitConfig("hk.production-apps") != "" {
+		t.Skip("hk.production-apps is set")
+	}
+	tests := map[string]bool{
+		"myapp-prod":       true,
+		"myapp-production": true,
+		"myapp-staging":    false,
+		"prod":             false,
+	}
+	for name, want := range tests {
+		if got := isProductionApp(name); got != want {
+			t.Errorf("isProductionApp(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	defer func(n int) { flagLimit = n }(flagLimit)
+	var tests = []struct {
+		flag, n, want int
+	}{
+		{0, 5, 5},
+		{3, 5, 3},
+		{5, 3, 3},
+		{2, 0, 0},
+	}
+	for _, tt := range tests {
+		flagLimit = tt.flag
+		if got := limit(tt.n); got != tt.want {
+			t.Errorf("--limit %d: limit(%d) = %d, want %d", tt.flag, tt.n, got, tt.want)
+		}
+	}
+}
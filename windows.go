@@ -24,7 +24,7 @@ func sysExec(path string, args []string, env []string) error {
 	if err != nil {
 		return err
 	}
-	os.Exit(0)
+	exit(0)
 	return nil
 }
 